@@ -0,0 +1,221 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command l8parse replays persisted collection jobs (as written by ParsingService into
+// ./jobsPersistency/) through the parser offline and prints the resulting objects as JSON.
+// It replays the models of replay.RegisterBootModels; jobs of the K8s resource models need
+// a binary importing their types and registering them with replay.RegisterModel.
+//
+// Usage:
+//
+//	l8parse [-model networkdevice] [-explain] <job file or directory>...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/saichler/l8parser/go/parser/replay"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// output is the JSON document printed per replayed job.
+type output struct {
+	File       string            `json:"file"`
+	Pollaris   string            `json:"pollaris,omitempty"`
+	Job        string            `json:"job,omitempty"`
+	HostId     string            `json:"hostId,omitempty"`
+	Model      string            `json:"model,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Element    json.RawMessage   `json:"element,omitempty"`
+	Instances  []json.RawMessage `json:"instances,omitempty"`
+	Attributes []*attribute      `json:"attributes,omitempty"`
+}
+
+// attribute is the explain output of a single poll attribute.
+type attribute struct {
+	PropertyId string   `json:"propertyId,omitempty"`
+	Skipped    bool     `json:"skipped,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Traces     []*trace `json:"traces,omitempty"`
+}
+
+// trace is the explain output of a single rule.
+type trace struct {
+	Rule         string   `json:"rule"`
+	PropertyId   string   `json:"propertyId,omitempty"`
+	RawValue     string   `json:"rawValue,omitempty"`
+	CoercedValue string   `json:"coercedValue,omitempty"`
+	SetAttempted bool     `json:"setAttempted,omitempty"`
+	SetOK        bool     `json:"setOk,omitempty"`
+	SetError     string   `json:"setError,omitempty"`
+	Notes        []string `json:"notes,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+func main() {
+	replay.RegisterBootModels()
+	model := flag.String("model", "", "Model to parse into, e.g. networkdevice. Inferred from the poll when empty.")
+	explain := flag.Bool("explain", false, "Include a per attribute, per rule trace of the parsing.")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: l8parse [-model name] [-explain] <job file or directory>...")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr, "Registered models:", replay.Models())
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	replayer := replay.NewReplayer(replay.NewResources(), *explain)
+	failed := false
+	for _, file := range files {
+		result := replayer.RunFile(file, *model)
+		out := toOutput(result, *explain)
+		if out.Error != "" {
+			failed = true
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, file, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Println(string(data))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// collectFiles expands directories into the regular files they contain, sorted.
+func collectFiles(args []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() && replay.IsJobFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func toOutput(result *replay.Result, explain bool) *output {
+	out := &output{File: result.File, Model: result.Model}
+	if result.Job != nil {
+		out.Pollaris = result.Job.PollarisName
+		out.Job = result.Job.JobName
+		out.HostId = result.Job.HostId
+	}
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+	}
+	if result.Parse == nil {
+		return out
+	}
+
+	out.Warnings = result.Parse.Warnings
+	out.Element = marshal(result.Parse.Element)
+	for _, inst := range result.Parse.Instances {
+		out.Instances = append(out.Instances, marshal(inst))
+	}
+	if !explain {
+		return out
+	}
+
+	for _, attrResult := range result.Parse.Attributes {
+		attr := &attribute{PropertyId: attrResult.PropertyId, Skipped: attrResult.Skipped}
+		if attrResult.Error != nil {
+			attr.Error = attrResult.Error.Error()
+		}
+		for _, ruleTrace := range attrResult.Traces {
+			attr.Traces = append(attr.Traces, &trace{
+				Rule:         ruleTrace.Rule,
+				PropertyId:   ruleTrace.PropertyId,
+				RawValue:     toString(ruleTrace.RawValue),
+				CoercedValue: toString(ruleTrace.CoercedValue),
+				SetAttempted: ruleTrace.SetAttempted,
+				SetOK:        ruleTrace.SetOK,
+				SetError:     ruleTrace.SetError,
+				Notes:        ruleTrace.Notes,
+				Error:        ruleTrace.Error,
+			})
+		}
+		out.Attributes = append(out.Attributes, attr)
+	}
+	return out
+}
+
+// marshal renders a parsed object as JSON, using protojson for protobuf messages.
+func marshal(any interface{}) json.RawMessage {
+	if any == nil {
+		return nil
+	}
+	if msg, ok := any.(proto.Message); ok {
+		data, err := protojson.Marshal(msg)
+		if err == nil {
+			return data
+		}
+	}
+	data, err := json.Marshal(any)
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+	}
+	return data
+}
+
+func toString(any interface{}) string {
+	if any == nil {
+		return ""
+	}
+	if msg, ok := any.(proto.Message); ok {
+		return string(marshal(msg))
+	}
+	if b, ok := any.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", any)
+}
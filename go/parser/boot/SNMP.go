@@ -31,6 +31,31 @@ import (
 	strings2 "github.com/saichler/l8utils/go/utils/strings"
 )
 
+// sysDescr patterns used by RegexExtract to pull the version, model and software out of
+// sysDescr, instead of setting the whole sysDescr on each of them. The first pattern of
+// each list is the main one, the others are fallbacks tried in order.
+var sysDescrVersionPatterns = []string{
+	`(?i)\bversion[:\s]+(?P<version>\d[\w.()\-]*[\w)])`,
+	`(?i)\b(?:JUNOS|EOS|PAN-OS|FortiOS|ExtremeXOS|ArubaOS|SonicOS|Comware|VRP|TiMOS-[\w.\-]+)\s+(?P<version>\d[\w.()\-]*[\w)])`,
+	`\bv(?P<version>\d+\.\d+[\w.()\-]*[\w)])`,
+	`\b(?P<version>\d+\.\d+\.\d+(?:[\w.()\-]*[\w)])?)`,
+}
+
+var sysDescrModelPatterns = []string{
+	`(?i)\b(?:model|platform|system type)\s*:\s*(?P<model>[A-Za-z0-9][\w\-/]+)`,
+	`Cisco (?P<model>(?:ASR|NCS|CRS)\d+\w*) Series`,
+	`\b(?P<model>(?:WS-C|C|ISR|ASR|CSR|N\dK-C)\d{3,4}[\w\-]*)\s+Software`,
+	`(?i)\bNX-OS(?:\(tm\))?\s+(?P<model>n\d+\w*)`,
+	`(?i)running on an?\s+(?:Arista Networks\s+)?(?P<model>[\w\-]+)`,
+	`Juniper Networks, Inc\.\s+(?P<model>[\w\-]+)`,
+	`\((?P<model>[A-Z]+\d+[\w\-]*)\s+V\d{3}R\d{3}`,
+	`\b(?P<model>PA-\d+\w*|FortiGate-\w+|DCS-[\w\-]+)`,
+}
+
+var sysDescrSoftwarePatterns = []string{
+	`(?i)\b(?P<software>Cisco IOS[ -]XE|Cisco IOS[ -]XR|Cisco NX-OS|Cisco IOS|JUNOS|Arista Networks EOS|PAN-OS|FortiOS|ExtremeXOS|ArubaOS|SonicOS|Comware|VRP|TiMOS|OS10|Linux)\b`,
+}
+
 // DEFAULT_CADENCE defines the standard polling intervals (5min, 15min, 1hr, 2hr) for SNMP collection.
 var DEFAULT_CADENCE = &l8tpollaris.L8PCadencePlan{Cadences: []int64{300, 900, 3600, 7200}, Enabled: true}
 
@@ -109,91 +134,13 @@ func CreateBoot03() *l8tpollaris.L8Pollaris {
 	return boot03
 }
 
-// GetPollarisByOid returns the appropriate vendor-specific Pollaris model based on sysOID
+// GetPollarisByOid returns the vendor-specific Pollaris model of the longest sysOID prefix
+// registered with rules.RegisterOid, see vendorOids and RegisterPollarisForOid.
 func GetPollarisByOid(sysOid string) *l8tpollaris.L8Pollaris {
-	// Cisco devices
-	if isCiscoOid(sysOid) {
-		if isCiscoSwitchOid(sysOid) {
-			return CreateCiscoSwitchBootPolls()
-		}
-		return CreateCiscoRouterBootPolls() // Default to router for Cisco
-	}
-
-	// Juniper devices
-	if isJuniperOid(sysOid) {
-		return CreateJuniperRouterBootPolls()
-	}
-
-	// Palo Alto Networks devices
-	if isPaloAltoOid(sysOid) {
-		return CreatePaloAltoFirewallBootPolls()
-	}
-
-	// Fortinet devices
-	if isFortinetOid(sysOid) {
-		return CreateFortinetFirewallBootPolls()
-	}
-
-	// Arista devices
-	if isAristaOid(sysOid) {
-		return CreateAristaSwitchBootPolls()
-	}
-
-	// Nokia devices
-	if isNokiaOid(sysOid) {
-		return CreateNokiaRouterBootPolls()
-	}
-
-	// Huawei devices
-	if isHuaweiOid(sysOid) {
-		return CreateHuaweiRouterBootPolls()
-	}
-
-	// Dell servers
-	if isDellOid(sysOid) {
-		return CreateDellServerBootPolls()
-	}
-
-	// HPE servers
-	if isHPEOid(sysOid) {
-		return CreateHPEServerBootPolls()
-	}
-
-	// SonicWall devices
-	if isSonicWallOid(sysOid) {
-		return CreateSonicWallFirewallBootPolls()
+	entry := rules.LookupOid(sysOid)
+	if entry != nil && entry.Pollaris != nil {
+		return entry.Pollaris()
 	}
-
-	// Check Point devices
-	if isCheckPointOid(sysOid) {
-		return CreateCheckPointFirewallBootPolls()
-	}
-
-	// Extreme devices
-	if isExtremeOid(sysOid) {
-		return CreateExtremeSwitchBootPolls()
-	}
-
-	// D-Link devices
-	if isDLinkOid(sysOid) {
-		return CreateDLinkSwitchBootPolls()
-	}
-
-	// IBM servers
-	if isIBMOid(sysOid) {
-		return CreateIBMServerBootPolls()
-	}
-
-	// NEC devices
-	if isNECOid(sysOid) {
-		return CreateNECRouterBootPolls()
-	}
-
-	// NVIDIA GPU servers
-	if isNvidiaOid(sysOid) {
-		return CreateNvidiaGpuBootPolls()
-	}
-
 	// Default to generic SNMP polling if no vendor match
 	return CreateBoot03()
 }
@@ -270,167 +217,6 @@ func GetAllPolarisModels() []*l8tpollaris.L8Pollaris {
 	return models
 }
 
-// OID matching helper functions
-func isCiscoOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.9.")
-}
-
-func isCiscoSwitchOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-
-	// Cisco switch specific OIDs (Catalyst series)
-	switchOids := []string{
-		".1.3.6.1.4.1.9.1.122",  // Catalyst 2960
-		".1.3.6.1.4.1.9.1.616",  // Catalyst 3560
-		".1.3.6.1.4.1.9.1.717",  // Catalyst 3750
-		".1.3.6.1.4.1.9.1.1208", // Catalyst 4500
-		".1.3.6.1.4.1.9.1.1146", // Catalyst 6500
-	}
-	for _, switchOid := range switchOids {
-		if strings.HasPrefix(normalizedOid, switchOid) {
-			return true
-		}
-	}
-	return false
-}
-
-func isJuniperOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.2636.")
-}
-
-func isPaloAltoOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.25461.")
-}
-
-func isFortinetOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.12356.")
-}
-
-func isAristaOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.30065.")
-}
-
-func isNokiaOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.6527.")
-}
-
-func isHuaweiOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.2011.")
-}
-
-func isDellOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.674.")
-}
-
-func isHPEOid(sysOid string) bool {
-	// Normalize OID by ensuring it starts with a dot
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.232.")
-}
-
-func isSonicWallOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.8714.")
-}
-
-func isCheckPointOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.2620.")
-}
-
-func isExtremeOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.1916.")
-}
-
-func isDLinkOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.171.")
-}
-
-func isIBMOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.2.6.")
-}
-
-func isNECOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.119.")
-}
-
-func isNvidiaOid(sysOid string) bool {
-	normalizedOid := sysOid
-	if !strings.HasPrefix(normalizedOid, ".") {
-		normalizedOid = "." + normalizedOid
-	}
-	return strings.HasPrefix(normalizedOid, ".1.3.6.1.4.1.53246.")
-}
-
 func createSystemMibPoll(p *l8tpollaris.L8Pollaris) {
 	poll := createBaseSNMPPoll("systemMib")
 	poll.What = ".1.3.6.1.2.1.1"
@@ -444,6 +230,8 @@ func createSystemMibPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = append(poll.Attributes, createSystemSoftware())    // networkdevice.equipmentinfo.software
 	poll.Attributes = append(poll.Attributes, createSystemVersion())     // networkdevice.equipmentinfo.version
 	poll.Attributes = append(poll.Attributes, createSystemModel())       // networkdevice.equipmentinfo.model
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())   // networkdevice.equipmentinfo.series
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())   // networkdevice.equipmentinfo.family
 	poll.Attributes = append(poll.Attributes, createSystemUptime())      // networkdevice.equipmentinfo.uptime
 	poll.Attributes = append(poll.Attributes, createSystemLocation())    // networkdevice.equipmentinfo.location
 	poll.Attributes = append(poll.Attributes, createSystemDeviceType())  // networkdevice.equipmentinfo.device_type
@@ -572,8 +360,8 @@ func createVendor() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.vendor", "gpudevice": "gpudevice.deviceinfo.vendor"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	attr.Rules = append(attr.Rules, createContainsRule("cisco", ".1.3.6.1.2.1.1.1.0", "Cisco"))
-	attr.Rules = append(attr.Rules, createContainsRule("ubuntu", ".1.3.6.1.2.1.1.1.0", "Ubuntu Linux"))
+	attr.Rules = append(attr.Rules, createOidToVendorRule(rules.OidVendor))                             // sysObjectID
+	attr.Rules = append(attr.Rules, createContainsRule("ubuntu", ".1.3.6.1.2.1.1.1.0", "Ubuntu Linux")) // net-snmp agent on a Linux host
 	return attr
 }
 
@@ -673,6 +461,42 @@ func createSetRule(from string) *l8tpollaris.L8PRule {
 	return rule
 }
 
+// createRegexExtractRule creates a RegexExtract rule setting the first named group of the
+// first matching pattern, e.g. "(?P<version>...)", on the attribute's property.
+func createRegexExtractRule(from string, patterns []string) *l8tpollaris.L8PRule {
+	rule := &l8tpollaris.L8PRule{}
+	rule.Name = "RegexExtract"
+	rule.Params = make(map[string]*l8tpollaris.L8PParameter)
+	addParameter("from", from, rule)
+	addParameter("pattern", patterns[0], rule)
+	if len(patterns) > 1 {
+		addParameter("fallback", strings.Join(patterns[1:], "\n"), rule)
+	}
+	return rule
+}
+
+// createOidToVendorRule creates an OidToVendor rule setting the vendor or the family of
+// the sysObjectID, e.g. rules.OidVendor, on the attribute's property.
+func createOidToVendorRule(field string) *l8tpollaris.L8PRule {
+	rule := &l8tpollaris.L8PRule{}
+	rule.Name = "OidToVendor"
+	rule.Params = make(map[string]*l8tpollaris.L8PParameter)
+	addParameter("from", ".1.3.6.1.2.1.1.2.0", rule) // sysObjectID
+	addParameter("field", field, rule)
+	return rule
+}
+
+// createSysDescrParseRule creates a SysDescrParse rule setting one field of the parsed
+// sysDescr, e.g. rules.SysDescrVersion, on the attribute's property.
+func createSysDescrParseRule(field string) *l8tpollaris.L8PRule {
+	rule := &l8tpollaris.L8PRule{}
+	rule.Name = "SysDescrParse"
+	rule.Params = make(map[string]*l8tpollaris.L8PParameter)
+	addParameter("from", ".1.3.6.1.2.1.1.1.0", rule) // sysDescr
+	addParameter("field", field, rule)
+	return rule
+}
+
 func createSetTimeSeriesRule(from string) *l8tpollaris.L8PRule {
 	rule := &l8tpollaris.L8PRule{}
 	rule.Name = "SetTimeSeries"
@@ -980,7 +804,8 @@ func createSoftwareAttribute() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.software", "gpudevice": "gpudevice.deviceinfo.osversion"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // sysDescr (contains software info)
+	attr.Rules = append(attr.Rules, createRegexExtractRule(".1.3.6.1.2.1.1.1.0", sysDescrSoftwarePatterns)) // sysDescr (extract software info)
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrSoftware))                        // refined for known platforms
 	return attr
 }
 
@@ -988,8 +813,7 @@ func createSeriesAttribute() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.series"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	// NOTE: Series typically derived from model parsing, not directly available
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // Parse from sysDescr
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrSeries)) // derived from the sysDescr model
 	return attr
 }
 
@@ -997,8 +821,8 @@ func createFamilyAttribute() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.family"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	// NOTE: Family typically derived from model parsing, not directly available
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // Parse from sysDescr
+	attr.Rules = append(attr.Rules, createOidToVendorRule(rules.OidFamily))        // sysObjectID
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrFamily)) // derived from the sysDescr model
 	return attr
 }
 
@@ -1740,7 +1564,8 @@ func createSystemSoftware() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.software", "gpudevice": "gpudevice.deviceinfo.osversion"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // sysDescr (extract software info)
+	attr.Rules = append(attr.Rules, createRegexExtractRule(".1.3.6.1.2.1.1.1.0", sysDescrSoftwarePatterns)) // sysDescr (extract software info)
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrSoftware))                        // refined for known platforms
 	return attr
 }
 
@@ -1748,7 +1573,8 @@ func createSystemVersion() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.version", "gpudevice": "gpudevice.deviceinfo.driverversion"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // sysDescr (extract version info)
+	attr.Rules = append(attr.Rules, createRegexExtractRule(".1.3.6.1.2.1.1.1.0", sysDescrVersionPatterns)) // sysDescr (extract version info)
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrVersion))                        // refined for known platforms
 	return attr
 }
 
@@ -1756,7 +1582,8 @@ func createSystemModel() *l8tpollaris.L8PAttribute {
 	attr := &l8tpollaris.L8PAttribute{}
 	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.equipmentinfo.model", "gpudevice": "gpudevice.deviceinfo.model"}
 	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
-	attr.Rules = append(attr.Rules, createSetRule(".1.3.6.1.2.1.1.1.0")) // sysDescr (extract model info)
+	attr.Rules = append(attr.Rules, createRegexExtractRule(".1.3.6.1.2.1.1.1.0", sysDescrModelPatterns)) // sysDescr (extract model info)
+	attr.Rules = append(attr.Rules, createSysDescrParseRule(rules.SysDescrModel))                        // refined for known platforms
 	return attr
 }
 
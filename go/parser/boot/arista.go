@@ -35,6 +35,7 @@ func CreateAristaSwitchBootPolls() *l8tpollaris.L8Pollaris {
 	createAristaTemperaturePoll(polaris)
 	createOspfPoll(polaris, "aristaOspf")
 	createBgpPoll(polaris, "aristaBgp")
+	createLldpPoll(polaris, "aristaLldp")
 	createVrfSshPoll(polaris, "aristaVrf", "show vrf detail", "eos")
 	return polaris
 }
@@ -56,6 +57,11 @@ func createAristaMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createAristaVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
@@ -35,6 +35,8 @@ func CreateCiscoSwitchBootPolls() *l8tpollaris.L8Pollaris {
 	createCiscoTemperaturePoll(polaris)
 	createOspfPoll(polaris, "ciscoSwitchOspf")
 	createBgpPoll(polaris, "ciscoSwitchBgp")
+	createLldpPoll(polaris, "ciscoSwitchLldp")
+	createCdpPoll(polaris, "ciscoSwitchCdp")
 	createVrfSshPoll(polaris, "ciscoSwitchVrf", "show ip vrf detail", "ios")
 	return polaris
 }
@@ -56,6 +58,8 @@ func CreateCiscoRouterBootPolls() *l8tpollaris.L8Pollaris {
 	createCiscoRoutingPoll(polaris)
 	createOspfPoll(polaris, "ciscoRouterOspf")
 	createBgpPoll(polaris, "ciscoRouterBgp")
+	createLldpPoll(polaris, "ciscoRouterLldp")
+	createCdpPoll(polaris, "ciscoRouterCdp")
 	createVrfSshPoll(polaris, "ciscoRouterVrf", "show vrf all detail", "iosxr")
 	return polaris
 }
@@ -76,6 +80,8 @@ func createCiscoSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = append(poll.Attributes, createUptimeAttribute())
 	poll.Attributes = append(poll.Attributes, createHardwareAttribute())
 	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
 	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
 	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
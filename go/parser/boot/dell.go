@@ -52,6 +52,11 @@ func createDellMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createDellVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
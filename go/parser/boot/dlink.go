@@ -32,6 +32,7 @@ func CreateDLinkSwitchBootPolls() *l8tpollaris.L8Pollaris {
 	createDLinkCpuPoll(polaris)
 	createDLinkMemoryPoll(polaris)
 	createDLinkTemperaturePoll(polaris)
+	createLldpPoll(polaris, "dLinkLldp")
 	return polaris
 }
 
@@ -52,6 +53,11 @@ func createDLinkMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createDLinkVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
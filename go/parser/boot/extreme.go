@@ -34,6 +34,7 @@ func CreateExtremeSwitchBootPolls() *l8tpollaris.L8Pollaris {
 	createExtremeTemperaturePoll(polaris)
 	createOspfPoll(polaris, "extremeOspf")
 	createBgpPoll(polaris, "extremeBgp")
+	createLldpPoll(polaris, "extremeLldp")
 	createVrfSshPoll(polaris, "extremeVrf", "show ip vrf detail", "voss")
 	return polaris
 }
@@ -55,6 +56,11 @@ func createExtremeMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createExtremeVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
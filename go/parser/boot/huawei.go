@@ -34,6 +34,7 @@ func CreateHuaweiRouterBootPolls() *l8tpollaris.L8Pollaris {
 	createHuaweiTemperaturePoll(polaris)
 	createOspfPoll(polaris, "huaweiOspf")
 	createBgpPoll(polaris, "huaweiBgp")
+	createLldpPoll(polaris, "huaweiLldp")
 	createVrfSshPoll(polaris, "huaweiVrf", "display ip vpn-instance verbose", "vrp")
 	return polaris
 }
@@ -55,6 +56,11 @@ func createHuaweiMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createHuaweiVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
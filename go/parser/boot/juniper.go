@@ -35,6 +35,7 @@ func CreateJuniperRouterBootPolls() *l8tpollaris.L8Pollaris {
 	createJuniperTemperaturePoll(polaris)
 	createOspfPoll(polaris, "juniperOspf")
 	createBgpPoll(polaris, "juniperBgp")
+	createLldpPoll(polaris, "juniperLldp")
 	createVrfSshPoll(polaris, "juniperVrf", "show route instance detail", "junos")
 	return polaris
 }
@@ -56,6 +57,11 @@ func createJuniperMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createJuniperVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
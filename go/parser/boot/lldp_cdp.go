@@ -0,0 +1,58 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boot
+
+import (
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// createLldpPoll creates an LLDP-MIB polling configuration (IEEE 802.1AB, same across all vendors).
+// Walks the lldpObjects subtree (.1.0.8802.1.1.2.1) to collect the local port table and
+// the remote systems table.
+func createLldpPoll(p *l8tpollaris.L8Pollaris, pollName string) {
+	poll := createBaseSNMPPoll(pollName)
+	poll.What = rules.LldpMibOid
+	poll.Operation = l8tpollaris.L8C_Operation_L8C_Map
+	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
+	poll.Attributes = append(poll.Attributes, createNeighborLinksAttribute("SnmpLldpToLinks"))
+	p.Polling[poll.Name] = poll
+}
+
+// createCdpPoll creates a CISCO-CDP-MIB polling configuration.
+// Walks the ciscoCdpMIBObjects subtree (.1.3.6.1.4.1.9.9.23.1) to collect the interface
+// table and the neighbor cache table.
+func createCdpPoll(p *l8tpollaris.L8Pollaris, pollName string) {
+	poll := createBaseSNMPPoll(pollName)
+	poll.What = rules.CdpMibOid
+	poll.Operation = l8tpollaris.L8C_Operation_L8C_Map
+	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
+	poll.Attributes = append(poll.Attributes, createNeighborLinksAttribute("SnmpCdpToLinks"))
+	p.Polling[poll.Name] = poll
+}
+
+// createNeighborLinksAttribute creates the attribute that maps a neighbor MIB walk result
+// to networkdevice.networklinks using the SnmpLldpToLinks or SnmpCdpToLinks bulk rule.
+func createNeighborLinksAttribute(ruleName string) *l8tpollaris.L8PAttribute {
+	attr := &l8tpollaris.L8PAttribute{}
+	attr.PropertyId = map[string]string{"networkdevice": "networkdevice.networklinks"}
+	attr.Rules = make([]*l8tpollaris.L8PRule, 0)
+	rule := &l8tpollaris.L8PRule{}
+	rule.Name = ruleName
+	rule.Params = make(map[string]*l8tpollaris.L8PParameter)
+	attr.Rules = append(attr.Rules, rule)
+	return attr
+}
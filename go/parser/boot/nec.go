@@ -34,6 +34,7 @@ func CreateNECRouterBootPolls() *l8tpollaris.L8Pollaris {
 	createNECTemperaturePoll(polaris)
 	createOspfPoll(polaris, "necOspf")
 	createBgpPoll(polaris, "necBgp")
+	createLldpPoll(polaris, "necLldp")
 	createVrfSshPoll(polaris, "necVrf", "show ip vrf detail", "univerge")
 	return polaris
 }
@@ -55,6 +56,11 @@ func createNECMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createNECVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
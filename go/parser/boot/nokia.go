@@ -34,6 +34,7 @@ func CreateNokiaRouterBootPolls() *l8tpollaris.L8Pollaris {
 	createNokiaTemperaturePoll(polaris)
 	createOspfPoll(polaris, "nokiaOspf")
 	createBgpPoll(polaris, "nokiaBgp")
+	createLldpPoll(polaris, "nokiaLldp")
 	createVrfSshPoll(polaris, "nokiaVrf", "show router vrf", "timos")
 	return polaris
 }
@@ -55,6 +56,11 @@ func createNokiaMibSystemPoll(p *l8tpollaris.L8Pollaris) {
 	poll.Attributes = make([]*l8tpollaris.L8PAttribute, 0)
 	poll.Attributes = append(poll.Attributes, createNokiaVendor())
 	poll.Attributes = append(poll.Attributes, createSysName())
+	poll.Attributes = append(poll.Attributes, createSoftwareAttribute())
+	poll.Attributes = append(poll.Attributes, createSystemModel())
+	poll.Attributes = append(poll.Attributes, createSystemVersion())
+	poll.Attributes = append(poll.Attributes, createSeriesAttribute())
+	poll.Attributes = append(poll.Attributes, createFamilyAttribute())
 	p.Polling[poll.Name] = poll
 }
 
@@ -0,0 +1,67 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// oid_registry.go maps the sysObjectID prefixes of the supported vendors to their
+// vendor name, device family and pollaris, see rules.RegisterOid.
+package boot
+
+import (
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// vendorOids are the sysObjectID prefixes of the vendors with a pollaris. The Cisco
+// switch sub-trees win over the Cisco prefix, whose devices default to the router polls.
+var vendorOids = []*rules.OidEntry{
+	{Prefix: ".1.3.6.1.4.1.9", Vendor: "Cisco", Pollaris: CreateCiscoRouterBootPolls},
+	{Prefix: ".1.3.6.1.4.1.9.1.122", Family: "Catalyst", Pollaris: CreateCiscoSwitchBootPolls},  // Catalyst 2960
+	{Prefix: ".1.3.6.1.4.1.9.1.616", Family: "Catalyst", Pollaris: CreateCiscoSwitchBootPolls},  // Catalyst 3560
+	{Prefix: ".1.3.6.1.4.1.9.1.717", Family: "Catalyst", Pollaris: CreateCiscoSwitchBootPolls},  // Catalyst 3750
+	{Prefix: ".1.3.6.1.4.1.9.1.1208", Family: "Catalyst", Pollaris: CreateCiscoSwitchBootPolls}, // Catalyst 4500
+	{Prefix: ".1.3.6.1.4.1.9.1.1146", Family: "Catalyst", Pollaris: CreateCiscoSwitchBootPolls}, // Catalyst 6500
+	{Prefix: ".1.3.6.1.4.1.2636", Vendor: "Juniper", Pollaris: CreateJuniperRouterBootPolls},
+	{Prefix: ".1.3.6.1.4.1.25461", Vendor: "Palo Alto Networks", Pollaris: CreatePaloAltoFirewallBootPolls},
+	{Prefix: ".1.3.6.1.4.1.12356", Vendor: "Fortinet", Pollaris: CreateFortinetFirewallBootPolls},
+	{Prefix: ".1.3.6.1.4.1.30065", Vendor: "Arista", Pollaris: CreateAristaSwitchBootPolls},
+	{Prefix: ".1.3.6.1.4.1.6527", Vendor: "Nokia", Pollaris: CreateNokiaRouterBootPolls},
+	{Prefix: ".1.3.6.1.4.1.2011", Vendor: "Huawei", Pollaris: CreateHuaweiRouterBootPolls},
+	{Prefix: ".1.3.6.1.4.1.674", Vendor: "Dell", Pollaris: CreateDellServerBootPolls},
+	{Prefix: ".1.3.6.1.4.1.232", Vendor: "Hewlett Packard Enterprise", Pollaris: CreateHPEServerBootPolls},
+	// 8741 is the SonicWall IANA enterprise number, 8714 is kept for the devices matched before
+	{Prefix: ".1.3.6.1.4.1.8741", Vendor: "SonicWall", Pollaris: CreateSonicWallFirewallBootPolls},
+	{Prefix: ".1.3.6.1.4.1.8714", Vendor: "SonicWall", Pollaris: CreateSonicWallFirewallBootPolls},
+	{Prefix: ".1.3.6.1.4.1.2620", Vendor: "Check Point", Pollaris: CreateCheckPointFirewallBootPolls},
+	{Prefix: ".1.3.6.1.4.1.1916", Vendor: "Extreme Networks", Pollaris: CreateExtremeSwitchBootPolls},
+	{Prefix: ".1.3.6.1.4.1.171", Vendor: "D-Link", Pollaris: CreateDLinkSwitchBootPolls},
+	{Prefix: ".1.3.6.1.4.1.2.6", Vendor: "IBM", Pollaris: CreateIBMServerBootPolls},
+	{Prefix: ".1.3.6.1.4.1.119", Vendor: "NEC", Pollaris: CreateNECRouterBootPolls},
+	{Prefix: ".1.3.6.1.4.1.53246", Vendor: "NVIDIA", Pollaris: CreateNvidiaGpuBootPolls},
+}
+
+func init() {
+	for _, entry := range vendorOids {
+		rules.RegisterOid(entry)
+	}
+}
+
+// RegisterPollarisForOid allows external projects to register the pollaris of the
+// devices of a sysObjectID prefix, returned by GetPollarisByOid for them. The pollaris
+// is also registered with RegisterPollaris so it is loaded on every node.
+func RegisterPollarisForOid(prefix, vendor, family string, pollaris func() *l8tpollaris.L8Pollaris) {
+	rules.RegisterOid(&rules.OidEntry{Prefix: prefix, Vendor: vendor, Family: family, Pollaris: pollaris})
+	if pollaris != nil {
+		RegisterPollaris(pollaris())
+	}
+}
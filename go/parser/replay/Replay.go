@@ -0,0 +1,241 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay parses persisted collection jobs offline, without the bus, the collector
+// or the inventory. It loads the boot pollaris models, resolves the poll of each job by its
+// pollaris and job names, and runs the parser against a fresh instance of the target model.
+package replay
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	"github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8reflect/go/reflect/introspecting"
+	"github.com/saichler/l8types/go/ifs"
+	"github.com/saichler/l8utils/go/utils/logger"
+	"github.com/saichler/l8utils/go/utils/registry"
+	"github.com/saichler/l8utils/go/utils/resources"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// modelInfo describes how to create an instance of a model for replay.
+type modelInfo struct {
+	factory    func() interface{}
+	primaryKey string
+}
+
+var models = make(map[string]*modelInfo)
+var modelsMtx = &sync.RWMutex{}
+
+// RegisterBootModels registers the models targeted by the boot pollaris configurations whose
+// types are shipped with probler: networkdevice and gpudevice, both keyed by the target's HostId.
+// The K8s resource models (k8spod, k8snode, ...) are defined by the project owning the K8s
+// types, which registers them with RegisterModel. Nothing is registered implicitly, so the
+// replayed models are always the ones the caller chose.
+func RegisterBootModels() {
+	RegisterModel("networkdevice", func() interface{} { return &types2.NetworkDevice{} }, "Id")
+	RegisterModel("gpudevice", func() interface{} { return &types2.GpuDevice{} }, "Id")
+}
+
+// RegisterModel registers the prototype factory of a model so jobs targeting it can be replayed.
+// modelName is the model key used in the attributes' PropertyId maps (e.g. "networkdevice"),
+// and primaryKey is the field set from the job's HostId, as ParsingService does. An empty
+// primaryKey leaves the instance key unset.
+func RegisterModel(modelName string, factory func() interface{}, primaryKey string) {
+	modelsMtx.Lock()
+	defer modelsMtx.Unlock()
+	models[modelName] = &modelInfo{factory: factory, primaryKey: primaryKey}
+}
+
+// Models returns the names of the registered models, sorted.
+func Models() []string {
+	modelsMtx.RLock()
+	defer modelsMtx.RUnlock()
+	result := make([]string, 0, len(models))
+	for name := range models {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func modelOf(modelName string) (*modelInfo, bool) {
+	modelsMtx.RLock()
+	defer modelsMtx.RUnlock()
+	info, ok := models[modelName]
+	return info, ok
+}
+
+// LoadJobFile loads a persisted protojson CJob from the given path, gzipped or not.
+func LoadJobFile(path string) (*l8tpollaris.CJob, error) {
+	return service.ReadJobFile(path)
+}
+
+// IsJobFile returns false for the files of a job store directory that are not jobs,
+// such as the per-target index.
+func IsJobFile(path string) bool {
+	name := filepath.Base(path)
+	return name != "index.json" && !strings.HasSuffix(name, ".tmp") && !strings.HasSuffix(name, ".md")
+}
+
+// NewResources creates standalone resources, with a registry and an introspector,
+// suitable for parsing outside of a running L8 node.
+func NewResources() ifs.IResources {
+	log := logger.NewLoggerImpl(&logger.FmtLogMethod{})
+	log.SetLogLevel(ifs.Info_Level)
+	res := resources.NewResources(log)
+	res.Set(registry.NewRegistry())
+	res.Set(introspecting.NewIntrospect(res.Registry()))
+	return res
+}
+
+// Replayer parses persisted jobs against the boot pollaris models.
+type Replayer struct {
+	resources ifs.IResources
+	polls     map[string]*l8tpollaris.L8Poll
+	explain   bool
+}
+
+// Result is the outcome of replaying a single job.
+type Result struct {
+	File  string
+	Job   *l8tpollaris.CJob
+	Model string
+	Parse *service.ParseResult
+	Error error
+}
+
+// NewReplayer creates a Replayer loading every pollaris of boot.GetAllPolarisModels, including
+// the externally registered ones. When explain is true jobs are parsed with Parser.Explain.
+func NewReplayer(res ifs.IResources, explain bool) *Replayer {
+	this := &Replayer{resources: res, explain: explain}
+	this.polls = make(map[string]*l8tpollaris.L8Poll)
+	res.Registry().Register(&l8tpollaris.CMap{})
+	res.Registry().Register(&l8tpollaris.CTable{})
+	res.Registry().Register(&l8tpollaris.CJob{})
+	for _, p := range boot.GetAllPolarisModels() {
+		for _, poll := range p.Polling {
+			this.polls[pollKey(p.Name, poll.Name)] = poll
+		}
+	}
+	for _, name := range Models() {
+		info, _ := modelOf(name)
+		res.Introspector().Inspect(info.factory())
+	}
+	return this
+}
+
+// Poll returns the poll configuration for the given pollaris and job names.
+func (this *Replayer) Poll(pollarisName, jobName string) (*l8tpollaris.L8Poll, bool) {
+	poll, ok := this.polls[pollKey(pollarisName, jobName)]
+	return poll, ok
+}
+
+// RunFile loads and replays a single persisted job file. See Run for modelName.
+func (this *Replayer) RunFile(path, modelName string) *Result {
+	job, err := LoadJobFile(path)
+	if err != nil {
+		return &Result{File: path, Error: err}
+	}
+	result := this.Run(job, modelName)
+	result.File = path
+	return result
+}
+
+// Run replays a job. When modelName is empty the model is inferred from the poll's
+// attributes: it must be the only registered model the attributes define a PropertyId for.
+func (this *Replayer) Run(job *l8tpollaris.CJob, modelName string) *Result {
+	result := &Result{Job: job}
+	poll, ok := this.Poll(job.PollarisName, job.JobName)
+	if !ok {
+		result.Error = errors.New("no poll " + job.PollarisName + ":" + job.JobName + " in the boot pollaris models")
+		return result
+	}
+
+	if modelName == "" {
+		modelName, result.Error = inferModel(poll)
+		if result.Error != nil {
+			return result
+		}
+	}
+	result.Model = modelName
+
+	info, ok := modelOf(modelName)
+	if !ok {
+		result.Error = errors.New("model " + modelName + " is not registered for replay, registered models are " +
+			joinModels())
+		return result
+	}
+
+	elem := info.factory()
+	setPrimaryKey(elem, info.primaryKey, job.HostId)
+	if this.explain {
+		result.Parse, result.Error = service.Parser.ExplainPoll(job, poll, modelName, elem, this.resources)
+	} else {
+		result.Parse, result.Error = service.Parser.ParsePoll(job, poll, modelName, elem, this.resources)
+	}
+	return result
+}
+
+// inferModel returns the single registered model the poll's attributes target.
+func inferModel(poll *l8tpollaris.L8Poll) (string, error) {
+	found := make(map[string]bool)
+	for _, attr := range poll.Attributes {
+		for model := range attr.PropertyId {
+			if _, ok := modelOf(model); ok {
+				found[model] = true
+			}
+		}
+	}
+	if len(found) == 1 {
+		for model := range found {
+			return model, nil
+		}
+	}
+	if len(found) == 0 {
+		return "", errors.New("poll " + poll.Name + " targets none of the registered models " + joinModels())
+	}
+	names := make([]string, 0, len(found))
+	for model := range found {
+		names = append(names, model)
+	}
+	sort.Strings(names)
+	return "", errors.New("poll " + poll.Name + " targets several models, select one of " + strings.Join(names, ", "))
+}
+
+func setPrimaryKey(elem interface{}, primaryKey, hostId string) {
+	if primaryKey == "" {
+		return
+	}
+	field := reflect.ValueOf(elem).Elem().FieldByName(primaryKey)
+	if field.IsValid() && field.CanSet() && field.Kind() == reflect.String {
+		field.SetString(hostId)
+	}
+}
+
+func pollKey(pollarisName, jobName string) string {
+	return pollarisName + ":" + jobName
+}
+
+func joinModels() string {
+	return strings.Join(Models(), ", ")
+}
@@ -18,6 +18,7 @@ package rules
 import (
 	"errors"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -37,7 +38,7 @@ func (this *CTableToInstances) Name() string {
 }
 
 func (this *CTableToInstances) ParamNames() []string {
-	return []string{""}
+	return []string{}
 }
 
 func (this *CTableToInstances) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
@@ -67,6 +68,10 @@ func (this *CTableToInstances) Parse(resources ifs.IResources, workSpace map[str
 	toString.TypesPrefix = false
 
 
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(table.Rows)) + " rows")
+	unmatched := make(map[string]bool)
+
 	instances := make([]interface{}, 0, len(table.Rows))
 	for _, row := range table.Rows {
 		inst := reflect.New(elemType)
@@ -92,6 +97,10 @@ func (this *CTableToInstances) Parse(resources ifs.IResources, workSpace map[str
 			attrName := getAttributeNameFromColumn(table.Columns[int32(i)])
 			field := findFieldByJsonName(instElem, attrName)
 			if !field.IsValid() || !field.CanSet() {
+				if trace != nil && !unmatched[attrName] {
+					unmatched[attrName] = true
+					trace.Note("column " + attrName + " has no field on " + elemType.Name())
+				}
 				continue
 			}
 			setFieldValue(field, val, resources)
@@ -111,6 +120,7 @@ func (this *CTableToInstances) Parse(resources ifs.IResources, workSpace map[str
 	}
 
 
+	trace.Note(strconv.Itoa(len(instances)) + " " + elemType.Name() + " instances")
 	workSpace[Instances] = instances
 	return nil
 }
@@ -18,6 +18,7 @@ package rules
 import (
 	"errors"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -39,7 +40,7 @@ func (this *CTableToMapProperty) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *CTableToMapProperty) ParamNames() []string {
-	return []string{""}
+	return []string{}
 }
 
 // Parse executes the CTableToMapProperty rule, mapping table data to object properties.
@@ -62,6 +63,9 @@ func (this *CTableToMapProperty) Parse(resources ifs.IResources, workSpace map[s
 	propertyId := workSpace[PropertyId].(string)
 	toString := strings2.New()
 	toString.TypesPrefix = true
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(table.Rows)) + " rows")
+	trace.Resolved(propertyId)
 
 	for _, row := range table.Rows {
 		pid := strings2.New(propertyId)
@@ -78,6 +82,7 @@ func (this *CTableToMapProperty) Parse(resources ifs.IResources, workSpace map[s
 				}
 				pid.Add(">.")
 				if !recOK {
+					trace.Note("row skipped, a key column has no value")
 					break
 				}
 			}
@@ -88,12 +93,14 @@ func (this *CTableToMapProperty) Parse(resources ifs.IResources, workSpace map[s
 			keyString := key.String()
 			prop, err := properties.PropertyOf(keyString, resources)
 			if err != nil {
+				trace.SetEach(keyString, err)
 				resources.Logger().Error(err.Error())
 				continue
 			}
 
 			val := getValue(row.Data[int32(i)], resources)
 			_, _, err = prop.Set(any, val)
+			trace.SetEach(keyString, err)
 			if err != nil {
 				resources.Logger().Error(err.Error())
 				continue
@@ -35,7 +35,16 @@ func (this *Contains) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *Contains) ParamNames() []string {
-	return []string{"what"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *Contains) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: What, Type: ParamString, Required: true, Help: "Lower case substring to look for in the input"},
+		{Name: From, Type: ParamString, Help: "Input map key to read, required for map input"},
+		{Name: Output, Type: ParamString, Required: true, Help: "Value set on the property when the substring is found"},
+	}
 }
 
 // Parse executes the Contains rule logic, checking if input contains the "what" substring.
@@ -63,18 +72,25 @@ func (this *Contains) Parse(resources ifs.IResources, workSpace map[string]inter
 	if err != nil {
 		return err
 	}
+	trace := traceOf(workSpace)
+	trace.Raw(str)
 	ok := strings.Contains(strings.ToLower(str), what.Value)
 	if ok {
 		if path != nil {
+			trace.Resolved(path.(string))
+			trace.Coerced(output.Value)
 			instance, _ := properties.PropertyOf(path.(string), resources)
 			if instance != nil {
 				_, _, err := instance.Set(any, output.Value)
+				trace.Set(err)
 				if err != nil {
 					return err
 				}
 			}
 		}
 		workSpace[Output] = output.Value
+	} else {
+		trace.Note("'" + what.Value + "' not found")
 	}
 	return nil
 }
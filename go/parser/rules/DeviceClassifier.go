@@ -0,0 +1,325 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// ClassifierRule is one row of the device type classifier table. It adds its Weight to the
+// score of its Type when all of its conditions hold; a negative Weight is evidence against
+// the Type. A rule has at least one condition.
+type ClassifierRule struct {
+	// Name identifies the rule in the evidence; registering a rule with the name of an
+	// existing one replaces it.
+	Name string `json:"name"`
+	// Type is the DeviceType without its "DEVICE_TYPE_" prefix, e.g. "ROUTER".
+	Type   string `json:"type"`
+	Weight int    `json:"weight"`
+	// OidPrefix matches the sysObjectID and its sub-tree, e.g. ".1.3.6.1.4.1.25461".
+	OidPrefix string `json:"oidPrefix,omitempty"`
+	// SysDescr is a regular expression matched case-insensitively against sysDescr.
+	SysDescr string `json:"sysDescr,omitempty"`
+	// Services are the sysServices bits that must all be set, e.g. 2 for the datalink layer.
+	Services int `json:"services,omitempty"`
+	// NotServices are the sysServices bits that must all be clear.
+	NotServices int `json:"notServices,omitempty"`
+	// Data is an OID prefix that must be present in the collected data, either as a
+	// polled OID or as a MIB module advertised in sysORTable, e.g. ".1.3.6.1.2.1.17" for
+	// the BRIDGE-MIB.
+	Data string `json:"data,omitempty"`
+}
+
+// DeviceFacts is what the classifier knows about a device.
+type DeviceFacts struct {
+	SysObjectID string
+	SysDescr    string
+	// SysServices is the sysServices value, zero when unknown.
+	SysServices int
+	// Oids are the OIDs present in the collected data and the sysORTable sysORID values.
+	Oids []string
+}
+
+// DeviceClassification is the device type chosen by the classifier.
+type DeviceClassification struct {
+	Type types2.DeviceType
+	// Confidence is between 0 and 1. It is the share of the winning type in the total
+	// score, scaled down while the winning score is below 100.
+	Confidence float64
+	// Evidence lists the rules that scored the winning type, e.g. "paloalto(+90)".
+	Evidence []string
+	// Scores holds the score of every type that was scored.
+	Scores map[string]int
+}
+
+// deviceClassifierJson is the built-in classifier table.
+//
+//go:embed device-classifier.json
+var deviceClassifierJson []byte
+
+// builtinClassifierRules is the built-in classifier table, parsed from
+// deviceClassifierJson at startup.
+var builtinClassifierRules []*ClassifierRule
+
+// classifierMu guards classifierRules.
+var classifierMu sync.RWMutex
+
+// classifierRules is the classifier table, the built-in one until rules are registered.
+// It is replaced, never modified, so a reader can keep using the table it got.
+var classifierRules []*ClassifierRule
+
+// init validates the built-in classifier table, so a broken embedded table fails the
+// process at startup and not a job while classifying a device.
+func init() {
+	entries, err := BuiltinClassifierRules()
+	if err != nil {
+		panic("built-in device-classifier.json: " + err.Error())
+	}
+	builtinClassifierRules = entries
+	classifierRules = entries
+}
+
+// deviceTypePrefix is the prefix of the DeviceType enum value names.
+const deviceTypePrefix = "DEVICE_TYPE_"
+
+// ParseClassifierRules parses and validates a JSON array of classifier rules.
+func ParseClassifierRules(data []byte) ([]*ClassifierRule, error) {
+	entries := make([]*ClassifierRule, 0)
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, errors.New("invalid classifier rules: " + err.Error())
+	}
+	for _, entry := range entries {
+		err = validateClassifierRule(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// LoadClassifierRules registers the classifier rules of a JSON file, so the table can be
+// extended without code changes.
+func LoadClassifierRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	entries, err := ParseClassifierRules(data)
+	if err != nil {
+		return errors.New(path + ": " + err.Error())
+	}
+	return RegisterClassifierRules(entries...)
+}
+
+// RegisterClassifierRules adds rules to the classifier table, replacing the rules with
+// the same name. It is safe to call more than once with the same rules.
+func RegisterClassifierRules(entries ...*ClassifierRule) error {
+	for _, entry := range entries {
+		err := validateClassifierRule(entry)
+		if err != nil {
+			return err
+		}
+	}
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	table := make([]*ClassifierRule, len(classifierRules), len(classifierRules)+len(entries))
+	copy(table, classifierRules)
+	for _, entry := range entries {
+		copyEntry := *entry
+		replaced := false
+		for i, existing := range table {
+			if existing.Name == entry.Name {
+				table[i] = &copyEntry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			table = append(table, &copyEntry)
+		}
+	}
+	classifierRules = table
+	return nil
+}
+
+// SetClassifierRules replaces the whole classifier table, e.g. to restore a table saved
+// with ClassifierRules. No rules restores the built-in table.
+func SetClassifierRules(entries ...*ClassifierRule) error {
+	for _, entry := range entries {
+		err := validateClassifierRule(entry)
+		if err != nil {
+			return err
+		}
+	}
+	table := make([]*ClassifierRule, len(entries))
+	for i, entry := range entries {
+		copyEntry := *entry
+		table[i] = &copyEntry
+	}
+	if len(table) == 0 {
+		table = builtinClassifierRules
+	}
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	classifierRules = table
+	return nil
+}
+
+// BuiltinClassifierRules parses the built-in classifier table, returning the error that
+// fails the startup when it is invalid.
+func BuiltinClassifierRules() ([]*ClassifierRule, error) {
+	return ParseClassifierRules(deviceClassifierJson)
+}
+
+// ClassifierRules returns a copy of the classifier table.
+func ClassifierRules() []*ClassifierRule {
+	table := classifierTable()
+	result := make([]*ClassifierRule, len(table))
+	for i, entry := range table {
+		copyEntry := *entry
+		result[i] = &copyEntry
+	}
+	return result
+}
+
+// classifierTable returns the current classifier table.
+func classifierTable() []*ClassifierRule {
+	classifierMu.RLock()
+	defer classifierMu.RUnlock()
+	return classifierRules
+}
+
+// ClassifyDevice scores every device type with the rules of the classifier table that
+// match the facts, and returns the type with the highest score. It returns
+// DEVICE_TYPE_UNKNOWN with no confidence when no type scores above zero.
+func ClassifyDevice(facts *DeviceFacts) *DeviceClassification {
+	result := &DeviceClassification{Type: types2.DeviceType(DEVICE_TYPE_UNKNOWN), Scores: make(map[string]int)}
+	if facts == nil {
+		return result
+	}
+	evidence := make(map[string][]string)
+	for _, entry := range classifierTable() {
+		if !entry.matches(facts) {
+			continue
+		}
+		result.Scores[entry.Type] += entry.Weight
+		evidence[entry.Type] = append(evidence[entry.Type], entry.Name+"("+signed(entry.Weight)+")")
+	}
+
+	winner, total := "", 0
+	for deviceType, score := range result.Scores {
+		if score <= 0 {
+			continue
+		}
+		total += score
+		// ties go to the type name that sorts first, so the result does not depend on the
+		// map iteration order
+		if winner == "" || score > result.Scores[winner] || (score == result.Scores[winner] && deviceType < winner) {
+			winner = deviceType
+		}
+	}
+	if winner == "" {
+		return result
+	}
+	score := result.Scores[winner]
+	result.Type = types2.DeviceType(types2.DeviceType_value[deviceTypePrefix+winner])
+	result.Confidence = float64(score) / float64(total)
+	if score < 100 {
+		result.Confidence = result.Confidence * float64(score) / 100
+	}
+	result.Evidence = evidence[winner]
+	return result
+}
+
+// matches returns true if all the conditions of the rule hold for the facts.
+func (this *ClassifierRule) matches(facts *DeviceFacts) bool {
+	if this.OidPrefix != "" && !hasOidPrefix(facts.SysObjectID, this.OidPrefix) {
+		return false
+	}
+	if this.SysDescr != "" {
+		if facts.SysDescr == "" {
+			return false
+		}
+		re, err := compileRegex("(?i)" + this.SysDescr)
+		if err != nil || !re.MatchString(facts.SysDescr) {
+			return false
+		}
+	}
+	if this.Services != 0 || this.NotServices != 0 {
+		if facts.SysServices == 0 {
+			return false
+		}
+		if facts.SysServices&this.Services != this.Services || facts.SysServices&this.NotServices != 0 {
+			return false
+		}
+	}
+	if this.Data != "" {
+		found := false
+		for _, oid := range facts.Oids {
+			if hasOidPrefix(oid, this.Data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasOidPrefix returns true if the OID is the prefix or in its sub-tree.
+func hasOidPrefix(oid, prefix string) bool {
+	oid = NormalizeOid(oid)
+	prefix = NormalizeOid(prefix)
+	return oid == prefix || strings.HasPrefix(oid, prefix+".")
+}
+
+func validateClassifierRule(entry *ClassifierRule) error {
+	if entry == nil || entry.Name == "" {
+		return errors.New("classifier rule without a name")
+	}
+	if _, ok := types2.DeviceType_value[deviceTypePrefix+entry.Type]; !ok {
+		return errors.New("classifier rule " + entry.Name + ": unknown device type '" + entry.Type + "'")
+	}
+	if entry.OidPrefix == "" && entry.SysDescr == "" && entry.Services == 0 &&
+		entry.NotServices == 0 && entry.Data == "" {
+		return errors.New("classifier rule " + entry.Name + " has no condition")
+	}
+	if entry.SysDescr != "" {
+		_, err := compileRegex("(?i)" + entry.SysDescr)
+		if err != nil {
+			return errors.New("classifier rule " + entry.Name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func signed(weight int) string {
+	if weight < 0 {
+		return strconv.Itoa(weight)
+	}
+	return "+" + strconv.Itoa(weight)
+}
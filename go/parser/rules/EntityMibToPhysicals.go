@@ -40,7 +40,7 @@ func (this *EntityMibToPhysicals) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *EntityMibToPhysicals) ParamNames() []string {
-	return []string{""}
+	return []string{}
 }
 
 // Entity MIB OID structure:
@@ -95,6 +95,8 @@ func (this *EntityMibToPhysicals) Parse(resources ifs.IResources, workSpace map[
 	if !ok {
 		return errors.New("Target object is not a NetworkDevice")
 	}
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(table.Rows)) + " rows")
 
 	// Ensure physicals map exists
 	if networkDevice.Physicals == nil {
@@ -239,7 +241,10 @@ func (this *EntityMibToPhysicals) Parse(resources ifs.IResources, workSpace map[
 		physical.Ports = make([]*types2.Port, 0, len(portMap))
 		for _, port := range portMap {
 			physical.Ports = append(physical.Ports, port)
+			trace.SetEach("networkdevice.physicals<"+physicalKey+">.ports<"+port.Id+"> "+port.Interfaces[0].Name, nil)
 		}
+	} else {
+		trace.Note("no entity of class port(10)")
 	}
 
 	return nil
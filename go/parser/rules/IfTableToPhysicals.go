@@ -38,7 +38,7 @@ func (this *IfTableToPhysicals) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *IfTableToPhysicals) ParamNames() []string {
-	return []string{""}
+	return []string{}
 }
 
 // Parse executes the IfTableToPhysicals rule, converting IF-MIB data to physical structures.
@@ -60,6 +60,8 @@ func (this *IfTableToPhysicals) Parse(resources ifs.IResources, workSpace map[st
 	if !ok {
 		return errors.New("Target object is not a NetworkDevice")
 	}
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(table.Rows)) + " rows")
 
 	// Ensure physicals map exists
 	if networkDevice.Physicals == nil {
@@ -257,6 +259,7 @@ func (this *IfTableToPhysicals) Parse(resources ifs.IResources, workSpace map[st
 
 		// Add interface to port
 		port.Interfaces = append(port.Interfaces, iface)
+		trace.SetEach("networkdevice.physicals<"+physicalKey+">.ports<"+ifIndexStr+">.interfaces "+iface.Name, nil)
 	}
 	return nil
 }
@@ -36,7 +36,14 @@ func (this *MapToDeviceStatus) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *MapToDeviceStatus) ParamNames() []string {
-	return []string{From}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *MapToDeviceStatus) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Required: true, Help: "Input map key holding the reachability map"},
+	}
 }
 
 // Parse executes the MapToDeviceStatus rule, converting status data to DeviceStatus enum.
@@ -57,6 +64,8 @@ func (this *MapToDeviceStatus) Parse(resources ifs.IResources, workSpace map[str
 	if value == nil {
 		return resources.Logger().Error("nil value for property id", propertyId)
 	}
+	trace := traceOf(workSpace)
+	trace.Raw(value)
 
 	// Convert map[int32]bool to device status enum
 	deviceStatus := problerTypes.DeviceStatus_DEVICE_STATUS_UNKNOWN
@@ -88,22 +97,28 @@ func (this *MapToDeviceStatus) Parse(resources ifs.IResources, workSpace map[str
 			}
 		} else {
 			// Try to handle other map types if needed
+			trace.Note("expected map[int32]bool, got a different map type")
 			resources.Logger().Error("Expected map[int32]bool, got different map type")
 			deviceStatus = problerTypes.DeviceStatus_DEVICE_STATUS_UNKNOWN
 		}
 	} else {
 		// If not a map, assume unknown
+		trace.Note("expected map[int32]bool, got " + kind.String())
 		resources.Logger().Error("Expected map[int32]bool for device status, got:", kind.String())
 		deviceStatus = problerTypes.DeviceStatus_DEVICE_STATUS_UNKNOWN
 	}
 
 	if _propertyId != nil {
+		trace.Resolved(propertyId)
+		trace.Coerced(deviceStatus.String())
 		instance, err := properties.PropertyOf(propertyId, resources)
 		if err != nil {
+			trace.Set(err)
 			return resources.Logger().Error("error parsing instance path", err.Error())
 		}
 		if instance != nil {
 			_, _, err := instance.Set(any, deviceStatus)
+			trace.Set(err)
 			if err != nil {
 				return resources.Logger().Error("error setting device status value:", err.Error())
 			}
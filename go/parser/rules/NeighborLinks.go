@@ -0,0 +1,392 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// Neighbor is a device discovered by a link layer discovery protocol, LLDP or CDP, on
+// one of the local ports.
+type Neighbor struct {
+	// Protocol is "lldp" or "cdp".
+	Protocol  string
+	LocalPort string
+	// RemoteChassisId is the LLDP chassis ID or the CDP device ID of the neighbor.
+	RemoteChassisId  string
+	RemotePort       string
+	RemoteSystemName string
+	// Capabilities are the enabled capabilities advertised by the neighbor, e.g. "bridge"
+	// and "router".
+	Capabilities []string
+}
+
+// Neighbor fields, the first field of the "fields" param entries of the neighbor rules.
+const (
+	NeighborId               = "id"
+	NeighborProtocol         = "protocol"
+	NeighborLocalPort        = "localport"
+	NeighborRemoteChassisId  = "remotechassisid"
+	NeighborRemotePort       = "remoteport"
+	NeighborRemoteSystemName = "remotesystemname"
+	NeighborCapabilities     = "capabilities"
+)
+
+// neighborLinkFields are the NetworkLink fields tried, in order, for each neighbor field
+// when the "fields" param does not name one. Neighbor fields with no matching NetworkLink
+// field are not set.
+var neighborLinkFields = []struct {
+	name  string
+	links []string
+}{
+	{NeighborId, []string{"linkid", "id"}},
+	{NeighborProtocol, []string{"protocol", "discoveryprotocol"}},
+	{NeighborLocalPort, []string{"localport", "localinterface", "frominterface", "sourceinterface"}},
+	{NeighborRemoteChassisId, []string{"remotechassisid", "remotedeviceid", "chassisid"}},
+	{NeighborRemotePort, []string{"remoteport", "remoteinterface", "tointerface", "targetinterface"}},
+	{NeighborRemoteSystemName, []string{"remotesystemname", "remotesysname", "remotename", "tonode"}},
+	{NeighborCapabilities, []string{"capabilities", "remotecapabilities"}},
+}
+
+// Id identifies the link to the neighbor by the local port and the remote chassis ID, so
+// polling the same neighbor again, over LLDP or CDP, replaces its link instead of adding
+// another one. The protocol is left out because a neighbor running both is one link;
+// a neighbor whose LLDP chassis ID differs from its CDP device ID, e.g. a MAC address and
+// a host name, still gets a link per protocol.
+func (this *Neighbor) Id() string {
+	return this.LocalPort + "|" + this.RemoteChassisId
+}
+
+// value returns the value of a neighbor field.
+func (this *Neighbor) value(name string) interface{} {
+	switch name {
+	case NeighborId:
+		return this.Id()
+	case NeighborProtocol:
+		return this.Protocol
+	case NeighborLocalPort:
+		return this.LocalPort
+	case NeighborRemoteChassisId:
+		return this.RemoteChassisId
+	case NeighborRemotePort:
+		return this.RemotePort
+	case NeighborRemoteSystemName:
+		return this.RemoteSystemName
+	case NeighborCapabilities:
+		return this.Capabilities
+	}
+	return nil
+}
+
+// SetNeighborLinks sets a NetworkLink entry for each neighbor on the NetworkLinks list of
+// the target model, replacing the entries with the same link ID. The fields param entries
+// are neighborField:linkField pairs naming the NetworkLink field of a neighbor field,
+// e.g. "remotesystemname:tonode"; the other neighbor fields are set on the first matching
+// field of neighborLinkFields. It returns the neighbor fields that were not set because
+// the NetworkLink has no such field.
+//
+// Links are only added or replaced, never removed: the link of a neighbor that is no
+// longer seen stays in the inventory. The neighbor polls are map polls, outside of the
+// Reconciler, and a link seen over both LLDP and CDP has no single poll owning it.
+func SetNeighborLinks(target interface{}, neighbors []*Neighbor, fields [][]string) ([]string, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("target is not a pointer to a struct")
+	}
+	links := findFieldByJsonName(v.Elem(), "networklinks")
+	if !links.IsValid() || !links.CanSet() || links.Kind() != reflect.Slice {
+		return nil, errors.New(v.Elem().Type().Name() + " has no NetworkLinks list")
+	}
+	elemType := links.Type().Elem()
+	linkType := elemType
+	if linkType.Kind() == reflect.Ptr {
+		linkType = linkType.Elem()
+	}
+	if linkType.Kind() != reflect.Struct {
+		return nil, errors.New("NetworkLinks is not a list of structs")
+	}
+
+	overrides := make(map[string]string)
+	for _, entry := range fields {
+		if (&Neighbor{}).value(entry[0]) == nil {
+			return nil, errors.New("unknown neighbor field '" + entry[0] + "'")
+		}
+		overrides[entry[0]] = entry[1]
+	}
+
+	// resolve the NetworkLink field of each neighbor field once
+	probe := reflect.New(linkType).Elem()
+	resolved := make(map[string]string)
+	unset := make([]string, 0)
+	for _, candidate := range neighborLinkFields {
+		names := candidate.links
+		if name, ok := overrides[candidate.name]; ok {
+			names = []string{name}
+		}
+		for _, name := range names {
+			if findFieldByJsonName(probe, name).IsValid() {
+				resolved[candidate.name] = name
+				break
+			}
+		}
+		if _, ok := resolved[candidate.name]; !ok {
+			unset = append(unset, candidate.name)
+		}
+	}
+
+	for _, neighbor := range neighbors {
+		link := reflect.New(linkType)
+		for name, linkField := range resolved {
+			setNeighborField(findFieldByJsonName(link.Elem(), linkField), neighbor.value(name))
+		}
+		entry := link
+		if elemType.Kind() != reflect.Ptr {
+			entry = link.Elem()
+		}
+		idField, hasId := resolved[NeighborId]
+		replaced := false
+		if hasId {
+			for i := 0; i < links.Len(); i++ {
+				existing := links.Index(i)
+				if existing.Kind() == reflect.Ptr {
+					if existing.IsNil() {
+						continue
+					}
+					existing = existing.Elem()
+				}
+				id := findFieldByJsonName(existing, idField)
+				if id.Kind() == reflect.String && id.String() == neighbor.Id() {
+					links.Index(i).Set(entry)
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			links.Set(reflect.Append(links, entry))
+		}
+	}
+	return unset, nil
+}
+
+// setNeighborField sets a neighbor field value on a NetworkLink field. Capabilities are
+// set on a list of strings, or joined with commas on a string field.
+func setNeighborField(field reflect.Value, value interface{}) {
+	if !field.IsValid() || !field.CanSet() {
+		return
+	}
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			setFieldValue(field, v, nil)
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+			list := reflect.MakeSlice(field.Type(), len(v), len(v))
+			for i, s := range v {
+				list.Index(i).SetString(s)
+			}
+			field.Set(list)
+		} else if field.Kind() == reflect.String {
+			field.SetString(strings.Join(v, ","))
+		}
+	}
+}
+
+// setNeighbors is the shared tail of the neighbor rules, setting the neighbors on the
+// target model and recording the outcome in the trace.
+func setNeighbors(resources ifs.IResources, ruleName string, workSpace map[string]interface{},
+	any interface{}, neighbors []*Neighbor, fields [][]string) error {
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(neighbors)) + " neighbors")
+	if len(neighbors) == 0 {
+		trace.Note("no neighbors")
+		return nil
+	}
+	if _propertyId, ok := workSpace[PropertyId].(string); ok {
+		trace.Resolved(_propertyId)
+	}
+	unset, err := SetNeighborLinks(any, neighbors, fields)
+	trace.Set(err)
+	if err != nil {
+		return resources.Logger().Error(ruleName, ": ", err.Error())
+	}
+	if len(unset) > 0 {
+		trace.Note("NetworkLink has no field for " + strings.Join(unset, ", "))
+	}
+	workSpace[Output] = neighbors
+	return nil
+}
+
+// decodeCMap decodes the values of the CMap entries under the prefixes, keyed by OID.
+// SNMP error strings, e.g. "noSuchInstance", are left out.
+func decodeCMap(cmap *l8tpollaris.CMap, resources ifs.IResources, prefixes ...string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for key, data := range cmap.Data {
+		key = NormalizeOid(key)
+		if len(data) == 0 || !hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		val, err := object.NewDecode(data, 0, resources.Registry()).Get()
+		if err != nil || val == nil {
+			continue
+		}
+		if s, ok := val.(string); ok && isSnmpErrorString(s) {
+			continue
+		}
+		values[key] = val
+	}
+	return values
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableColumn returns the rows of a table column, keyed by the row index. The column
+// OID is the table entry OID followed by the column number, e.g. ".1.0.8802.1.1.2.1.4.1.1.5".
+func tableColumn(values map[string]interface{}, columnOid string) map[string]interface{} {
+	prefix := NormalizeOid(columnOid) + "."
+	rows := make(map[string]interface{})
+	for key, val := range values {
+		key = NormalizeOid(key)
+		if strings.HasPrefix(key, prefix) {
+			rows[key[len(prefix):]] = val
+		}
+	}
+	return rows
+}
+
+// indexArcs parses a table row index into its arcs, nil if it is not numeric.
+func indexArcs(index string) []int {
+	parts := strings.Split(index, ".")
+	arcs := make([]int, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		arcs[i] = arc
+	}
+	return arcs
+}
+
+// sortIndexes sorts table row indexes by their arcs.
+func sortIndexes(indexes []string) {
+	sort.Slice(indexes, func(i, j int) bool {
+		a, b := indexArcs(indexes[i]), indexArcs(indexes[j])
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+}
+
+// octets returns the bytes of an OCTET STRING value.
+func octets(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+// displayString returns an OCTET STRING value as text. Binary values, such as MAC
+// addresses, are returned as colon separated hex, e.g. "00:1a:2b:3c:4d:5e".
+func displayString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	b := octets(value)
+	if b == nil {
+		return fmt.Sprintf("%v", value)
+	}
+	b = []byte(strings.TrimRight(string(b), "\x00"))
+	if isPrintable(b) {
+		return strings.TrimSpace(string(b))
+	}
+	return hexOctets(b)
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hexOctets(b []byte) string {
+	parts := make([]string, len(b))
+	for i := range b {
+		parts[i] = hex.EncodeToString(b[i : i+1])
+	}
+	return strings.Join(parts, ":")
+}
+
+// bitsOctets returns the bytes of a BITS or capability mask value. Agents return it as
+// the raw octets, as hex text such as "28 00" or "0x2800", or as a number.
+func bitsOctets(value interface{}) []byte {
+	if s, ok := value.(string); ok {
+		text := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "0x")
+		text = strings.NewReplacer(" ", "", ":", "").Replace(text)
+		if len(text) >= 2 && len(text)%2 == 0 && len(s) > 2 {
+			if b, err := hex.DecodeString(text); err == nil {
+				return b
+			}
+		}
+	}
+	if b := octets(value); b != nil {
+		return b
+	}
+	if n, ok := toInt64(value); ok {
+		b := make([]byte, 0, 8)
+		for n > 0 {
+			b = append([]byte{byte(n & 0xff)}, b...)
+			n = n >> 8
+		}
+		return b
+	}
+	return nil
+}
@@ -18,7 +18,6 @@ package rules
 import (
 	"reflect"
 	"strconv"
-	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
 	"github.com/saichler/l8reflect/go/reflect/properties"
@@ -42,7 +41,15 @@ func (this *NormalizeEnum) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *NormalizeEnum) ParamNames() []string {
-	return []string{"map"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *NormalizeEnum) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "map", Type: ParamMappingList, Required: true, Fields: 2,
+			Help: "Comma-separated input:output pairs, * maps any other value"},
+	}
 }
 
 // Parse executes the NormalizeEnum rule logic.
@@ -59,11 +66,14 @@ func (this *NormalizeEnum) Parse(resources ifs.IResources, workSpace map[string]
 	propertyId := _propertyId.(string)
 
 	// Parse the value mapping from params
-	mapParam, ok := params["map"]
-	if !ok || mapParam == nil || mapParam.Value == "" {
-		return resources.Logger().Error("NormalizeEnum: missing 'map' parameter")
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("NormalizeEnum: ", err.Error())
 	}
-	valueMap, defaultVal := parseValueMap(mapParam.Value)
+	valueMap, defaultVal := parseValueMap(typed.Mappings("map"))
+
+	trace := traceOf(workSpace)
+	trace.Raw(output)
 
 	// Convert the output value to an int64 for lookup
 	inputKey, valid := toInt64(output)
@@ -81,13 +91,17 @@ func (this *NormalizeEnum) Parse(resources ifs.IResources, workSpace map[string]
 	}
 
 	// Set the normalized value on the target property
-	modifiedPropertyId := injectIndexOrKey(propertyId, workSpace)
+	modifiedPropertyId := InjectIndexOrKey(propertyId, workSpace)
+	trace.Resolved(modifiedPropertyId)
+	trace.Coerced(enumVal)
 	instance, err := properties.PropertyOf(modifiedPropertyId, resources)
 	if err != nil {
+		trace.Set(err)
 		return resources.Logger().Error("NormalizeEnum: error resolving property:", err.Error())
 	}
 	if instance != nil {
 		_, _, err = instance.Set(any, enumVal)
+		trace.Set(err)
 		if err != nil {
 			return resources.Logger().Error("NormalizeEnum: error setting property:", err.Error())
 		}
@@ -97,20 +111,18 @@ func (this *NormalizeEnum) Parse(resources ifs.IResources, workSpace map[string]
 	return nil
 }
 
-// parseValueMap parses a mapping string like "1:1,2:2,3:0,*:0" into a lookup map
+// parseValueMap converts the parsed "map" pairs (e.g. "1:1,2:2,3:0,*:0") into a lookup map
 // and a default value. Returns (map[inputVal]outputVal, defaultVal).
-func parseValueMap(mapStr string) (map[int64]int32, int32) {
+func parseValueMap(pairs [][]string) (map[int64]int32, int32) {
 	result := make(map[int64]int32)
 	var defaultVal int32
 
-	pairs := strings.Split(mapStr, ",")
-	for _, pair := range pairs {
-		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+	for _, parts := range pairs {
 		if len(parts) != 2 {
 			continue
 		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
+		key := parts[0]
+		val := parts[1]
 
 		outVal, err := strconv.ParseInt(val, 10, 32)
 		if err != nil {
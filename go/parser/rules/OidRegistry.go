@@ -0,0 +1,201 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"sync"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// EnterprisesOid is the prefix of the sysObjectID of vendor devices, followed by the
+// IANA enterprise number of the vendor.
+const EnterprisesOid = ".1.3.6.1.4.1"
+
+// OidEntry tells what devices of a sysObjectID prefix are.
+type OidEntry struct {
+	// Prefix is the sysObjectID prefix, e.g. ".1.3.6.1.4.1.9" for every Cisco device or
+	// ".1.3.6.1.4.1.9.1.122" for a Catalyst 2960.
+	Prefix string
+	Vendor string
+	// Family is the device family of the prefix, e.g. "Catalyst", empty when unknown.
+	Family string
+	// Pollaris creates the pollaris for the devices of the prefix, nil when they use the
+	// pollaris of a shorter prefix.
+	Pollaris func() *l8tpollaris.L8Pollaris
+}
+
+// oidRegistryMu guards oidRegistry.
+var oidRegistryMu sync.RWMutex
+
+// oidRegistry maps a normalized sysObjectID prefix to its entry.
+var oidRegistry = map[string]*OidEntry{}
+
+// RegisterOid registers what devices of a sysObjectID prefix are. An entry of a longer
+// prefix wins over the entries of its shorter prefixes, so product sub-trees can be
+// registered under the vendor's enterprise prefix. Registering a prefix again replaces it.
+//
+// Example:
+//
+//	rules.RegisterOid(&rules.OidEntry{
+//	    Prefix:   ".1.3.6.1.4.1.9.1.122",
+//	    Vendor:   "Cisco",
+//	    Family:   "Catalyst",
+//	    Pollaris: boot.CreateCiscoSwitchBootPolls,
+//	})
+func RegisterOid(entry *OidEntry) {
+	if entry == nil || entry.Prefix == "" {
+		return
+	}
+	copyEntry := *entry
+	copyEntry.Prefix = NormalizeOid(entry.Prefix)
+	oidRegistryMu.Lock()
+	defer oidRegistryMu.Unlock()
+	oidRegistry[copyEntry.Prefix] = &copyEntry
+}
+
+// LookupOid returns the entry of the longest registered prefix of the sysObjectID, or
+// nil when none is registered. The fields that entry leaves empty are taken from the
+// entry of the next shorter prefix that has them.
+func LookupOid(sysOid string) *OidEntry {
+	oid := NormalizeOid(sysOid)
+	oidRegistryMu.RLock()
+	defer oidRegistryMu.RUnlock()
+	var result *OidEntry
+	for oid != "" {
+		entry, ok := oidRegistry[oid]
+		if ok {
+			if result == nil {
+				copyEntry := *entry
+				result = &copyEntry
+			}
+			if result.Vendor == "" {
+				result.Vendor = entry.Vendor
+			}
+			if result.Family == "" {
+				result.Family = entry.Family
+			}
+			if result.Pollaris == nil {
+				result.Pollaris = entry.Pollaris
+			}
+			if result.Vendor != "" && result.Family != "" && result.Pollaris != nil {
+				break
+			}
+		}
+		oid = oid[:strings.LastIndex(oid, ".")]
+	}
+	return result
+}
+
+// NormalizeOid returns the OID with a leading dot and no trailing dot or spaces.
+func NormalizeOid(oid string) string {
+	oid = strings.TrimSuffix(strings.TrimSpace(oid), ".")
+	if oid != "" && !strings.HasPrefix(oid, ".") {
+		oid = "." + oid
+	}
+	return oid
+}
+
+// EnterpriseNumber returns the IANA enterprise number of a vendor sysObjectID, e.g. "9"
+// for ".1.3.6.1.4.1.9.1.122", or false when it is not under EnterprisesOid.
+func EnterpriseNumber(sysOid string) (string, bool) {
+	oid := NormalizeOid(sysOid)
+	if !strings.HasPrefix(oid, EnterprisesOid+".") {
+		return "", false
+	}
+	number := strings.SplitN(oid[len(EnterprisesOid)+1:], ".", 2)[0]
+	return number, number != ""
+}
+
+// enterpriseNumbers is a curated subset of the IANA Private Enterprise Numbers registry,
+// one "number<TAB>name" per line. It only holds about 90 network, server, storage and
+// facility equipment vendors, not the whole registry, so the other enterprise numbers have
+// no name until they are added to enterprise-numbers.txt or with RegisterEnterprise.
+//
+//go:embed enterprise-numbers.txt
+var enterpriseNumbers string
+
+// enterprisesMu guards enterprises.
+var enterprisesMu sync.RWMutex
+
+// enterprises maps an IANA enterprise number to the vendor name. It is loaded from
+// enterpriseNumbers on first use.
+var enterprises map[string]string
+
+// RegisterEnterprise adds or replaces the vendor name of an IANA enterprise
+// number, for vendors that are not in the curated bundled table, e.g.
+// rules.RegisterEnterprise("3375", "F5 Networks").
+func RegisterEnterprise(number, name string) {
+	if number == "" || name == "" {
+		return
+	}
+	enterprisesMu.Lock()
+	defer enterprisesMu.Unlock()
+	loadEnterprises()
+	enterprises[number] = name
+}
+
+// EnterpriseName returns the vendor name of the IANA enterprise number of a
+// sysObjectID, or empty when it is neither in the curated bundled table nor registered.
+func EnterpriseName(sysOid string) string {
+	number, ok := EnterpriseNumber(sysOid)
+	if !ok {
+		return ""
+	}
+	enterprisesMu.RLock()
+	if enterprises != nil {
+		defer enterprisesMu.RUnlock()
+		return enterprises[number]
+	}
+	enterprisesMu.RUnlock()
+	enterprisesMu.Lock()
+	defer enterprisesMu.Unlock()
+	loadEnterprises()
+	return enterprises[number]
+}
+
+// VendorOf returns the vendor of a sysObjectID, from the registered prefixes first and
+// from the IANA enterprise names otherwise. It returns empty when neither knows it.
+func VendorOf(sysOid string) string {
+	entry := LookupOid(sysOid)
+	if entry != nil && entry.Vendor != "" {
+		return entry.Vendor
+	}
+	return EnterpriseName(sysOid)
+}
+
+// loadEnterprises parses enterpriseNumbers once, enterprisesMu must be locked.
+func loadEnterprises() {
+	if enterprises != nil {
+		return
+	}
+	enterprises = make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(enterpriseNumbers))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		enterprises[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+}
@@ -0,0 +1,114 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// OidToVendor fields, the values of the OidToVendor "field" param.
+const (
+	OidVendor = "vendor"
+	OidFamily = "family"
+)
+
+// OidToVendor is a parsing rule that sets the vendor, or the device family, of the
+// sysObjectID on the attribute's property. The vendor comes from the prefixes registered
+// with RegisterOid, and from the IANA enterprise names for the others (see VendorOf).
+// Nothing is set when the sysObjectID is not known, so a following rule such as Contains
+// can still set it from sysDescr.
+type OidToVendor struct{}
+
+// Name returns the rule identifier "OidToVendor".
+func (this *OidToVendor) Name() string {
+	return "OidToVendor"
+}
+
+// ParamNames returns the required parameter names for this rule.
+func (this *OidToVendor) ParamNames() []string {
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *OidToVendor) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Help: "Input map key holding sysObjectID, required for map input"},
+		{Name: "field", Type: ParamEnum, Default: OidVendor, Help: "What is set on the property",
+			EnumValues: []string{OidVendor, OidFamily}},
+	}
+}
+
+// Parse executes the OidToVendor rule logic.
+func (this *OidToVendor) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	input := workSpace[Input]
+	if input == nil {
+		return resources.Logger().Error("nil input for job")
+	}
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("OidToVendor: ", err.Error())
+	}
+
+	trace := traceOf(workSpace)
+	value, kind, err := GetValueInput(resources, input, params, pollWhat)
+	if err != nil || value == nil {
+		if err != nil {
+			trace.Note("skipped, no value: " + err.Error())
+		}
+		return nil
+	}
+	sysOid, err := convertToString(value, kind)
+	if err != nil {
+		return resources.Logger().Error("OidToVendor: ", err.Error())
+	}
+	trace.Raw(sysOid)
+
+	result := ""
+	if typed.String("field") == OidFamily {
+		if entry := LookupOid(sysOid); entry != nil {
+			result = entry.Family
+		}
+	} else {
+		result = VendorOf(sysOid)
+	}
+	if result == "" {
+		trace.Note("no " + typed.String("field") + " known for " + sysOid)
+		return nil
+	}
+
+	_propertyId := workSpace[PropertyId]
+	if _propertyId != nil {
+		propertyId := InjectIndexOrKey(_propertyId.(string), workSpace)
+		trace.Resolved(propertyId)
+		trace.Coerced(result)
+		instance, err := properties.PropertyOf(propertyId, resources)
+		if err != nil {
+			trace.Set(err)
+			return resources.Logger().Error("OidToVendor: error resolving property:", err.Error())
+		}
+		if instance != nil {
+			_, _, err = instance.Set(any, result)
+			trace.Set(err)
+			if err != nil {
+				return resources.Logger().Error("OidToVendor: error setting property:", err.Error())
+			}
+		}
+	}
+	workSpace[Output] = result
+	return nil
+}
@@ -39,4 +39,7 @@ const (
 	Instances = "instances"
 	// TargetId is the workspace key for the collection job's target ID (e.g., cluster name).
 	TargetId = "target_id"
+	// Required is the parameter name marking an attribute as required; when such an
+	// attribute fails, the whole job is aborted regardless of the parser's error policy.
+	Required = "required"
 )
@@ -0,0 +1,359 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	l8strings "github.com/saichler/l8utils/go/utils/strings"
+)
+
+// ParamType describes how a rule parameter value is parsed.
+type ParamType int
+
+const (
+	// ParamString is a free text value.
+	ParamString ParamType = iota
+	// ParamInt is a base 10 integer.
+	ParamInt
+	// ParamIntList is a list of integers, either in the type-prefixed l8utils string
+	// format produced by the boot helpers or as a plain comma-separated list.
+	ParamIntList
+	// ParamEnum is a value restricted to ParamSpec.EnumValues.
+	ParamEnum
+	// ParamMappingList is a comma-separated list of colon-separated entries,
+	// e.g. "1:devicename:set,2:deviceuuid:set". ParamSpec.Fields sets the entry arity.
+	ParamMappingList
+	// ParamOID is a dotted numeric SNMP OID, with or without a leading dot.
+	ParamOID
+	// ParamJSONPath is a dot-path into a JSON document, e.g. "devices.0.name".
+	ParamJSONPath
+	// ParamBool is a boolean as accepted by strconv.ParseBool.
+	ParamBool
+	// ParamRegex is a regular expression as accepted by regexp.Compile.
+	ParamRegex
+	// ParamRegexList is a list of regular expressions, one per line.
+	ParamRegexList
+)
+
+// String returns the display name of the parameter type.
+func (this ParamType) String() string {
+	switch this {
+	case ParamString:
+		return "string"
+	case ParamInt:
+		return "int"
+	case ParamIntList:
+		return "int list"
+	case ParamEnum:
+		return "enum"
+	case ParamMappingList:
+		return "mapping list"
+	case ParamOID:
+		return "OID"
+	case ParamJSONPath:
+		return "JSONPath"
+	case ParamBool:
+		return "bool"
+	case ParamRegex:
+		return "regex"
+	case ParamRegexList:
+		return "regex list"
+	}
+	return "ParamType(" + strconv.Itoa(int(this)) + ")"
+}
+
+// ParamSpec describes a single rule parameter.
+type ParamSpec struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	// Default is used when the parameter is absent. Empty means no default.
+	Default string
+	// Help is a one line description used for documentation and authoring tools.
+	Help string
+	// EnumValues lists the accepted values of a ParamEnum parameter.
+	EnumValues []string
+	// Fields is the number of colon-separated fields of each ParamMappingList entry.
+	// Zero accepts any entry with at least two fields.
+	Fields int
+}
+
+// SchemaRule is optionally implemented by a ParsingRule to describe its parameters.
+// When a rule implements it, the parser parses the rule's params according to the
+// schema before invoking the rule and stores the result in the workspace under
+// TypedParamsKey, and the pollaris validator checks params against the schema.
+type SchemaRule interface {
+	ParamSchema() []*ParamSpec
+}
+
+// TypedParamsKey is the workspace key holding the *TypedParams of the rule being executed.
+const TypedParamsKey = "typed_params"
+
+// TypedParams holds rule parameters parsed according to a ParamSchema.
+type TypedParams struct {
+	values map[string]interface{}
+}
+
+// Has returns true if the parameter was provided or has a default.
+func (this *TypedParams) Has(name string) bool {
+	_, ok := this.values[name]
+	return ok
+}
+
+// String returns the value of a ParamString, ParamEnum, ParamOID, ParamJSONPath or
+// ParamRegex parameter.
+func (this *TypedParams) String(name string) string {
+	v, _ := this.values[name].(string)
+	return v
+}
+
+// Int returns the value of a ParamInt parameter.
+func (this *TypedParams) Int(name string) int {
+	v, _ := this.values[name].(int)
+	return v
+}
+
+// IntList returns the value of a ParamIntList parameter.
+func (this *TypedParams) IntList(name string) []int {
+	v, _ := this.values[name].([]int)
+	return v
+}
+
+// Mappings returns the entries of a ParamMappingList parameter, each split into its fields.
+func (this *TypedParams) Mappings(name string) [][]string {
+	v, _ := this.values[name].([][]string)
+	return v
+}
+
+// RegexList returns the value of a ParamRegexList parameter.
+func (this *TypedParams) RegexList(name string) []string {
+	v, _ := this.values[name].([]string)
+	return v
+}
+
+// Bool returns the value of a ParamBool parameter.
+func (this *TypedParams) Bool(name string) bool {
+	v, _ := this.values[name].(bool)
+	return v
+}
+
+// ParseParams parses the rule params according to the schema. It fails on a missing
+// required parameter or on a value that does not parse as its declared type.
+// Parameters not described by the schema are ignored.
+//
+// As the rules did before they had a schema, a few malformed values are tolerated at
+// run time: an invalid value of a parameter with a default falls back to the default,
+// and the malformed entries of a mapping list are skipped as long as one entry is valid.
+// ParseParamValue, used by the pollaris validator, still reports them.
+func ParseParams(schema []*ParamSpec, params map[string]*l8tpollaris.L8PParameter) (*TypedParams, error) {
+	typed := &TypedParams{values: make(map[string]interface{})}
+	for _, spec := range schema {
+		value, ok := "", false
+		if param, exists := params[spec.Name]; exists && param != nil {
+			value, ok = param.Value, true
+		}
+		if !ok && spec.Default != "" {
+			value, ok = spec.Default, true
+		}
+		if !ok {
+			if spec.Required {
+				return nil, errors.New("missing required parameter '" + spec.Name + "'")
+			}
+			continue
+		}
+		parsed, err := parseParamValue(spec, value, true)
+		if err != nil && spec.Default != "" && value != spec.Default {
+			parsed, err = parseParamValue(spec, spec.Default, true)
+		}
+		if err != nil {
+			return nil, err
+		}
+		typed.values[spec.Name] = parsed
+	}
+	return typed, nil
+}
+
+// ParseParamValue parses a single parameter value according to its spec.
+func ParseParamValue(spec *ParamSpec, value string) (interface{}, error) {
+	return parseParamValue(spec, value, false)
+}
+
+// parseParamValue parses a parameter value, skipping the malformed entries of a mapping
+// list when lenient is true.
+func parseParamValue(spec *ParamSpec, value string, lenient bool) (interface{}, error) {
+	switch spec.Type {
+	case ParamInt:
+		i, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, paramError(spec, value)
+		}
+		return i, nil
+	case ParamIntList:
+		return parseIntList(spec, value)
+	case ParamEnum:
+		for _, enumValue := range spec.EnumValues {
+			if enumValue == value {
+				return value, nil
+			}
+		}
+		return nil, errors.New("parameter '" + spec.Name + "' value '" + value + "' is not one of " +
+			strings.Join(spec.EnumValues, ", "))
+	case ParamMappingList:
+		return parseMappingList(spec, value, lenient)
+	case ParamOID:
+		if !isOid(value) {
+			return nil, paramError(spec, value)
+		}
+		return value, nil
+	case ParamJSONPath:
+		if value == "" || strings.ContainsAny(value, " \t\n") ||
+			strings.HasPrefix(value, ".") || strings.HasSuffix(value, ".") {
+			return nil, paramError(spec, value)
+		}
+		return value, nil
+	case ParamBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, paramError(spec, value)
+		}
+		return b, nil
+	case ParamRegex:
+		if _, err := regexp.Compile(value); err != nil {
+			return nil, errors.New("parameter '" + spec.Name + "' value '" + value + "' is not a valid regex: " + err.Error())
+		}
+		return value, nil
+	case ParamRegexList:
+		return parseRegexList(spec, value)
+	}
+	return value, nil
+}
+
+// RequiredParamNames returns the names of the required parameters of a schema,
+// so a SchemaRule can implement ParamNames from its schema.
+func RequiredParamNames(schema []*ParamSpec) []string {
+	names := make([]string, 0, len(schema))
+	for _, spec := range schema {
+		if spec.Required {
+			names = append(names, spec.Name)
+		}
+	}
+	return names
+}
+
+// typedParams returns the typed params prepared by the parser for the executing rule,
+// parsing them on the spot when the rule is invoked directly (e.g. from a test).
+func typedParams(rule SchemaRule, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter) (*TypedParams, error) {
+	if typed, ok := workSpace[TypedParamsKey].(*TypedParams); ok && typed != nil {
+		return typed, nil
+	}
+	return ParseParams(rule.ParamSchema(), params)
+}
+
+func parseIntList(spec *ParamSpec, value string) ([]int, error) {
+	arr, err := l8strings.FromString(value, nil)
+	if err == nil {
+		if ints, ok := arr.Interface().([]int); ok {
+			return ints, nil
+		}
+	}
+	result := make([]int, 0)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i, e := strconv.Atoi(part)
+		if e != nil {
+			return nil, paramError(spec, value)
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+func parseMappingList(spec *ParamSpec, value string, lenient bool) ([][]string, error) {
+	result := make([][]string, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var fields []string
+		if spec.Fields > 0 {
+			fields = strings.SplitN(entry, ":", spec.Fields)
+		} else {
+			fields = strings.Split(entry, ":")
+		}
+		if (spec.Fields > 0 && len(fields) != spec.Fields) || len(fields) < 2 {
+			if lenient {
+				continue
+			}
+			return nil, errors.New("parameter '" + spec.Name + "' entry '" + entry + "' is not a valid mapping")
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		result = append(result, fields)
+	}
+	if len(result) == 0 {
+		return nil, paramError(spec, value)
+	}
+	return result, nil
+}
+
+func parseRegexList(spec *ParamSpec, value string) ([]string, error) {
+	result := make([]string, 0)
+	for _, line := range strings.Split(value, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if _, err := regexp.Compile(line); err != nil {
+			return nil, errors.New("parameter '" + spec.Name + "' value '" + line + "' is not a valid regex: " + err.Error())
+		}
+		result = append(result, line)
+	}
+	if len(result) == 0 {
+		return nil, paramError(spec, value)
+	}
+	return result, nil
+}
+
+func isOid(value string) bool {
+	oid := strings.TrimPrefix(value, ".")
+	if oid == "" {
+		return false
+	}
+	for _, part := range strings.Split(oid, ".") {
+		if part == "" {
+			return false
+		}
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func paramError(spec *ParamSpec, value string) error {
+	return errors.New("parameter '" + spec.Name + "' value '" + value + "' is not a valid " + spec.Type.String())
+}
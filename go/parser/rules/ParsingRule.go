@@ -16,7 +16,8 @@ limitations under the License.
 // Package rules provides the parsing rule engine for L8Parser.
 // It defines the ParsingRule interface and implements various rule types
 // for data transformation including Contains, Set, StringToCTable, CTableToMapProperty,
-// EntityMibToPhysicals, IfTableToPhysicals, InferDeviceType, and MapToDeviceStatus.
+// EntityMibToPhysicals, IfTableToPhysicals, InferDeviceType, MapToDeviceStatus, RegexExtract,
+// SysDescrParse, OidToVendor, SnmpLldpToLinks and SnmpCdpToLinks.
 package rules
 
 import (
@@ -37,7 +38,8 @@ import (
 type ParsingRule interface {
 	// Name returns the unique identifier for this rule type.
 	Name() string
-	// ParamNames returns the list of parameter names this rule expects.
+	// ParamNames returns the list of parameter names this rule requires.
+	// The pollaris validator reports any rule missing one of these parameters.
 	ParamNames() []string
 	// Parse executes the rule logic, transforming input data and storing results in the workspace.
 	// Parameters: resources (system resources), workspace (rule workspace), params (rule parameters),
@@ -106,9 +108,9 @@ func GetValueInput(resources ifs.IResources, input interface{}, params map[strin
 	return nil, reflect.Invalid, errors.New("unsupported input type")
 }
 
-// injectIndexOrKey injects slice indices or map keys into PropertyId paths
+// InjectIndexOrKey injects slice indices or map keys into PropertyId paths
 // Format: <{reflect.Kind}value> before the attribute that needs indexing
-func injectIndexOrKey(propertyId string, workSpace map[string]interface{}) string {
+func InjectIndexOrKey(propertyId string, workSpace map[string]interface{}) string {
 	// Map of collection attributes that need indexing/keying
 	collectionMappings := map[string]string{
 		"physicals":      "{24}physical-0", // map<string, Physical> - use string key
@@ -209,18 +211,6 @@ func isSnmpErrorString(s string) bool {
 		strings.Contains(lower, "nosuchinstance")
 }
 
-func getIntInput(workSpace map[string]interface{}, paramName string) (int, error) {
-	v, ok := workSpace[paramName].(string)
-	if !ok {
-		return -1, errors.New("'" + paramName + "' does not exist")
-	}
-	i, e := strconv.Atoi(v)
-	if e != nil {
-		return -1, e
-	}
-	return i, nil
-}
-
 func getIntArrInput(workSpace map[string]interface{}, paramName string) ([]int, error) {
 	v, ok := workSpace[paramName].(string)
 	if !ok {
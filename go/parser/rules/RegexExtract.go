@@ -0,0 +1,203 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// RegexExtract is a parsing rule that pulls structured fields out of a free text value,
+// such as the version and model out of sysDescr. The "pattern" regular expression names
+// the fields with capture groups, e.g. "Version (?P<version>[^,\s]+)"; when it does not
+// match, the "fallback" patterns are tried in order.
+//
+// The value of "group" (by default the first named group that matched) is set on the
+// attribute's PropertyId. The "mapping" param sets other groups on other properties of
+// the same model, e.g. "serial:networkdevice.equipmentinfo.serialnumber". Nothing is set
+// when no pattern matches, so the property is not filled with the whole text.
+type RegexExtract struct{}
+
+// regexCache holds the compiled patterns, keyed by pattern.
+var regexCache = &sync.Map{}
+
+// Name returns the rule identifier "RegexExtract".
+func (this *RegexExtract) Name() string {
+	return "RegexExtract"
+}
+
+// ParamNames returns the required parameter names for this rule.
+func (this *RegexExtract) ParamNames() []string {
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *RegexExtract) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Help: "Input map key to read, required for map input"},
+		{Name: "pattern", Type: ParamRegex, Required: true, Help: "Regular expression naming the fields with (?P<name>...) groups"},
+		{Name: "fallback", Type: ParamRegexList, Help: "Patterns tried in order when pattern does not match, one per line"},
+		{Name: "group", Type: ParamString, Help: "Group set on the attribute's PropertyId, defaults to the first named group that matched"},
+		{Name: "mapping", Type: ParamMappingList, Fields: 2, Help: "Comma-separated group:PropertyId pairs of other groups to set"},
+	}
+}
+
+// Parse executes the RegexExtract rule logic.
+func (this *RegexExtract) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	input := workSpace[Input]
+	if input == nil {
+		return resources.Logger().Error("nil input for job")
+	}
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("RegexExtract: ", err.Error())
+	}
+
+	trace := traceOf(workSpace)
+	value, kind, err := GetValueInput(resources, input, params, pollWhat)
+	if err != nil || value == nil {
+		// Missing/blank OID data is expected for some devices — skip gracefully
+		if err != nil {
+			trace.Note("skipped, no value: " + err.Error())
+		}
+		return nil
+	}
+	text, err := convertToString(value, kind)
+	if err != nil {
+		return resources.Logger().Error("RegexExtract: ", err.Error())
+	}
+	trace.Raw(text)
+	if isSnmpErrorString(text) {
+		trace.Note("skipped, SNMP error string")
+		return nil
+	}
+
+	patterns := append([]string{typed.String("pattern")}, typed.RegexList("fallback")...)
+	groups, first, err := ExtractGroups(text, patterns...)
+	if err != nil {
+		return resources.Logger().Error("RegexExtract: ", err.Error())
+	}
+	if groups == nil {
+		trace.Note("no pattern matched")
+		return nil
+	}
+
+	group := first
+	if typed.Has("group") {
+		group = typed.String("group")
+	}
+	_propertyId := workSpace[PropertyId]
+	if _propertyId != nil && groups[group] != "" {
+		propertyId := _propertyId.(string)
+		err = setExtracted(resources, workSpace, propertyId, groups[group], any)
+		trace.Resolved(InjectIndexOrKey(propertyId, workSpace))
+		trace.Coerced(groups[group])
+		trace.Set(err)
+		if err != nil {
+			return err
+		}
+		workSpace[Output] = groups[group]
+	}
+
+	for _, entry := range typed.Mappings("mapping") {
+		groupValue := groups[entry[0]]
+		if groupValue == "" || !sameModel(entry[1], _propertyId) {
+			continue
+		}
+		err = setExtracted(resources, workSpace, entry[1], groupValue, any)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractGroups matches text against the patterns in order and returns the named groups
+// of the first pattern that matches, trimmed, with the name of its first non empty group.
+// It returns nil groups when no pattern matches.
+func ExtractGroups(text string, patterns ...string) (map[string]string, string, error) {
+	for _, pattern := range patterns {
+		re, err := compileRegex(pattern)
+		if err != nil {
+			return nil, "", err
+		}
+		match := re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		groups := make(map[string]string)
+		first := ""
+		for i, name := range re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			value := strings.TrimSpace(match[i])
+			if value == "" {
+				continue
+			}
+			groups[name] = value
+			if first == "" {
+				first = name
+			}
+		}
+		return groups, first, nil
+	}
+	return nil, "", nil
+}
+
+// compileRegex returns the compiled pattern, compiling it once.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// sameModel returns true if the PropertyId belongs to the model of the attribute's
+// PropertyId, e.g. both start with "networkdevice.".
+func sameModel(propertyId string, attributePropertyId interface{}) bool {
+	current, ok := attributePropertyId.(string)
+	if !ok {
+		return true
+	}
+	return strings.SplitN(propertyId, ".", 2)[0] == strings.SplitN(current, ".", 2)[0]
+}
+
+// setExtracted sets an extracted value on the PropertyId.
+func setExtracted(resources ifs.IResources, workSpace map[string]interface{}, propertyId string, value string, any interface{}) error {
+	instance, err := properties.PropertyOf(InjectIndexOrKey(propertyId, workSpace), resources)
+	if err != nil {
+		return resources.Logger().Error("RegexExtract: error resolving property:", err.Error())
+	}
+	if instance == nil {
+		return nil
+	}
+	_, _, err = instance.Set(any, value)
+	if err != nil {
+		return resources.Logger().Error("RegexExtract: error setting property:", err.Error())
+	}
+	return nil
+}
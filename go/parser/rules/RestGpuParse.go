@@ -43,7 +43,17 @@ func (this *RestGpuParse) Name() string {
 }
 
 func (this *RestGpuParse) ParamNames() []string {
-	return []string{"array_path", "mapping"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *RestGpuParse) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "array_path", Type: ParamJSONPath, Required: true, Help: "Dot-path to the GPU array in the JSON"},
+		{Name: "mapping", Type: ParamMappingList, Required: true, Fields: 2,
+			Help: "Comma-separated jsonField:propertyName pairs"},
+		{Name: "key_field", Type: ParamString, Default: "pci_bus_id", Help: "JSON field used as the GPU map key"},
+	}
 }
 
 func (this *RestGpuParse) Parse(resources ifs.IResources, workSpace map[string]interface{},
@@ -84,9 +94,13 @@ func (this *RestGpuParse) Parse(resources ifs.IResources, workSpace map[string]i
 		return errors.New("RestGpuParse: missing 'array_path' or 'mapping' parameter")
 	}
 
+	trace := traceOf(workSpace)
+	trace.Raw(jsonStr)
+
 	// Get the GPU array from JSON
 	arrValue := getJsonValue(jsonData, arrayPathParam.Value)
 	if arrValue == nil {
+		trace.Note("no value at " + arrayPathParam.Value)
 		return nil
 	}
 	gpuArray, ok := arrValue.([]interface{})
@@ -130,15 +144,17 @@ func (this *RestGpuParse) Parse(resources ifs.IResources, workSpace map[string]i
 		}
 		mapKey, ok := gpuMap[keyField].(string)
 		if !ok || mapKey == "" {
+			trace.Note("GPU skipped, no " + keyField)
 			continue
 		}
 		mapKey = strings.TrimSpace(mapKey)
 		if !isValidPciBusId(mapKey) {
+			trace.Note("GPU skipped, invalid PCI bus ID " + mapKey)
 			continue
 		}
 
 		// Set the PCI Bus ID as a property on the GPU instance
-		setGpuProperty(resources, propertyId, mapKey, "pcibusid", mapKey, any)
+		setGpuProperty(resources, trace, propertyId, mapKey, "pcibusid", mapKey, any)
 
 		for _, m := range mappings {
 			val, exists := gpuMap[m.jsonField]
@@ -148,10 +164,12 @@ func (this *RestGpuParse) Parse(resources ifs.IResources, workSpace map[string]i
 			fullId := fmt.Sprintf("%s<{24}%s>.%s", propertyId, mapKey, m.propertyName)
 			instance, err := properties.PropertyOf(fullId, resources)
 			if err != nil || instance == nil {
+				trace.SetEach(fullId, propertyError(err))
 				continue
 			}
 			coerced := coerceJsonValue(val, instance, resources, workSpace)
-			instance.Set(any, coerced)
+			_, _, err = instance.Set(any, coerced)
+			trace.SetEach(fullId, err)
 		}
 	}
 
@@ -43,7 +43,15 @@ func (this *RestJsonParse) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *RestJsonParse) ParamNames() []string {
-	return []string{"mapping"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *RestJsonParse) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "mapping", Type: ParamMappingList, Required: true, Fields: 2,
+			Help: "Comma-separated jsonPath:propertyId pairs"},
+	}
 }
 
 // Parse executes the RestJsonParse rule logic.
@@ -79,7 +87,10 @@ func (this *RestJsonParse) Parse(resources ifs.IResources, workSpace map[string]
 		return errors.New("RestJsonParse: failed to parse JSON: " + err.Error())
 	}
 
+	trace := traceOf(workSpace)
+	trace.Raw(jsonStr)
 	if len(jsonData) == 0 {
+		trace.Note("empty JSON document")
 		return nil
 	}
 
@@ -100,26 +111,30 @@ func (this *RestJsonParse) Parse(resources ifs.IResources, workSpace map[string]
 
 		value := getJsonValue(jsonData, jsonPath)
 		if value == nil {
+			trace.Note("no value at " + jsonPath)
 			continue
 		}
 
 		// Handle array values for repeated fields
 		if arr, ok := value.([]interface{}); ok {
-			setRepeatedProperty(resources, targetPropId, arr, any)
+			setRepeatedProperty(resources, targetPropId, arr, any, trace)
 			continue
 		}
 
 		// Set scalar value
-		modifiedId := injectIndexOrKey(targetPropId, workSpace)
+		modifiedId := InjectIndexOrKey(targetPropId, workSpace)
 		instance, err := properties.PropertyOf(modifiedId, resources)
 		if err != nil || instance == nil {
+			trace.SetEach(modifiedId, propertyError(err))
 			continue
 		}
 		coerced := coerceJsonValue(value, instance, resources, workSpace)
 		if coerced == nil {
+			trace.Note("cannot coerce " + jsonPath + " for " + modifiedId)
 			continue
 		}
-		instance.Set(any, coerced)
+		_, _, err = instance.Set(any, coerced)
+		trace.SetEach(modifiedId, err)
 	}
 
 	return nil
@@ -144,7 +159,7 @@ func getJsonValue(data map[string]interface{}, path string) interface{} {
 }
 
 // setRepeatedProperty sets values from a JSON array onto repeated protobuf fields.
-func setRepeatedProperty(resources ifs.IResources, propertyId string, arr []interface{}, any interface{}) {
+func setRepeatedProperty(resources ifs.IResources, propertyId string, arr []interface{}, any interface{}, trace *RuleTrace) {
 	for i, item := range arr {
 		itemMap, ok := item.(map[string]interface{})
 		if !ok {
@@ -152,13 +167,15 @@ func setRepeatedProperty(resources ifs.IResources, propertyId string, arr []inte
 		}
 		for key, val := range itemMap {
 			fullId := fmt.Sprintf("%s<{2}%d>.%s", propertyId, i, key)
-			fullId = injectIndexOrKey(fullId, nil)
+			fullId = InjectIndexOrKey(fullId, nil)
 			instance, err := properties.PropertyOf(fullId, resources)
 			if err != nil || instance == nil {
+				trace.SetEach(fullId, propertyError(err))
 				continue
 			}
 			coerced := coerceJsonValue(val, instance, resources, nil)
-			instance.Set(any, coerced)
+			_, _, err = instance.Set(any, coerced)
+			trace.SetEach(fullId, err)
 		}
 	}
 }
@@ -211,3 +228,12 @@ func coerceJsonValue(value interface{}, instance *properties.Property, resources
 	// Fallback: use the existing coerceValue for non-basic types
 	return coerceValue(resources, value, instance, workSpace)
 }
+
+// propertyError returns the error of a property that could not be resolved, which may
+// be nil when the property was resolved to nothing.
+func propertyError(err error) error {
+	if err != nil {
+		return err
+	}
+	return errors.New("no such property")
+}
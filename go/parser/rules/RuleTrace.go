@@ -0,0 +1,114 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+// TraceKey is the workspace key holding the *RuleTrace of the rule being executed.
+// It is only present when the parser runs in explain mode.
+const TraceKey = "trace"
+
+// RuleTrace records what a single rule did while parsing an attribute in explain mode.
+// All recording methods are nil-safe, so rules can call them unconditionally and pay
+// nothing when explain mode is off.
+type RuleTrace struct {
+	// Rule is the name of the executed rule.
+	Rule string
+	// PropertyId is the property path after InjectIndexOrKey.
+	PropertyId string
+	// RawValue is the value extracted from the input, e.g. by GetValueInput.
+	RawValue interface{}
+	// CoercedValue is the value handed to instance.Set after type coercion.
+	CoercedValue interface{}
+	// SetAttempted is true when the rule tried to set the property.
+	SetAttempted bool
+	// SetOK is true when instance.Set succeeded.
+	SetOK bool
+	// SetError holds the instance.Set or property resolution error, if any.
+	SetError string
+	// SetCount is the number of properties set by a rule setting many, such as a table rule.
+	SetCount int
+	// Notes holds free form observations, such as why a value was skipped.
+	Notes []string
+	// Error holds the error returned by the rule, if any.
+	Error string
+}
+
+// traceOf returns the trace of the executing rule, or nil when explain mode is off.
+func traceOf(workSpace map[string]interface{}) *RuleTrace {
+	trace, _ := workSpace[TraceKey].(*RuleTrace)
+	return trace
+}
+
+// Resolved records the PropertyId after index/key injection.
+func (this *RuleTrace) Resolved(propertyId string) {
+	if this != nil {
+		this.PropertyId = propertyId
+	}
+}
+
+// Raw records the value extracted from the input.
+func (this *RuleTrace) Raw(value interface{}) {
+	if this != nil {
+		this.RawValue = value
+	}
+}
+
+// Coerced records the value handed to instance.Set.
+func (this *RuleTrace) Coerced(value interface{}) {
+	if this != nil {
+		this.CoercedValue = value
+	}
+}
+
+// Set records the outcome of setting the property.
+func (this *RuleTrace) Set(err error) {
+	if this == nil {
+		return
+	}
+	this.SetAttempted = true
+	this.SetOK = err == nil
+	if err != nil {
+		this.SetError = err.Error()
+	}
+}
+
+// Note records a free form observation.
+func (this *RuleTrace) Note(note string) {
+	if this != nil {
+		this.Notes = append(this.Notes, note)
+	}
+}
+
+// SetEach records the outcome of setting one of the many properties of a bulk rule.
+// SetOK stays true while every property was set, SetError holds the first failure and
+// each failure is noted with its property path.
+func (this *RuleTrace) SetEach(propertyId string, err error) {
+	if this == nil {
+		return
+	}
+	if !this.SetAttempted {
+		this.SetAttempted = true
+		this.SetOK = true
+	}
+	if err == nil {
+		this.SetCount++
+		return
+	}
+	this.SetOK = false
+	if this.SetError == "" {
+		this.SetError = propertyId + ": " + err.Error()
+	}
+	this.Notes = append(this.Notes, "cannot set "+propertyId+": "+err.Error())
+}
@@ -37,7 +37,14 @@ func (this *Set) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *Set) ParamNames() []string {
-	return []string{}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *Set) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Help: "Input map key to read, required for map input"},
+	}
 }
 
 // Parse executes the Set rule logic, extracting a value and setting it to the target property.
@@ -50,30 +57,40 @@ func (this *Set) Parse(resources ifs.IResources, workSpace map[string]interface{
 		return resources.Logger().Error("nil input for job")
 	}
 
+	trace := traceOf(workSpace)
 	value, _, err := GetValueInput(resources, input, params, pollWhat)
 	if err != nil || value == nil {
 		// Missing/blank OID data is expected for some devices — skip gracefully
+		if err != nil {
+			trace.Note("skipped, no value: " + err.Error())
+		}
 		return nil
 	}
+	trace.Raw(value)
 
 	// Skip SNMP error strings gracefully - device doesn't support this OID
 	if strVal, ok := value.(string); ok {
 		if isSnmpErrorString(strVal) {
+			trace.Note("skipped, SNMP error string")
 			return nil
 		}
 	}
 
 	if _propertyId != nil {
 		// Inject slice index or map key into PropertyId before creating property instance
-		modifiedPropertyId := injectIndexOrKey(propertyId, workSpace)
+		modifiedPropertyId := InjectIndexOrKey(propertyId, workSpace)
+		trace.Resolved(modifiedPropertyId)
 
 		instance, err := properties.PropertyOf(modifiedPropertyId, resources)
 		if err != nil {
+			trace.Set(err)
 			return resources.Logger().Error("error parsing instance path", err.Error())
 		}
 		if instance != nil {
 			value = coerceValue(resources, value, instance, workSpace)
+			trace.Coerced(value)
 			_, _, err = instance.Set(any, value)
+			trace.Set(err)
 			if err != nil {
 				return resources.Logger().Error("error setting property value:", err.Error())
 			}
@@ -39,7 +39,14 @@ func (this *SetTimeSeries) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *SetTimeSeries) ParamNames() []string {
-	return []string{From}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SetTimeSeries) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Required: true, Help: "Input map key holding the metric value"},
+	}
 }
 
 // Parse executes the SetTimeSeries rule logic.
@@ -54,6 +61,7 @@ func (this *SetTimeSeries) Parse(resources ifs.IResources, workSpace map[string]
 		return resources.Logger().Error("nil input for SetTimeSeries")
 	}
 
+	trace := traceOf(workSpace)
 	value, kind, err := GetValueInput(resources, input, params, pollWhat)
 	if err != nil {
 		return err
@@ -62,10 +70,12 @@ func (this *SetTimeSeries) Parse(resources ifs.IResources, workSpace map[string]
 	if value == nil {
 		return resources.Logger().Error("nil value for property id", propertyId)
 	}
+	trace.Raw(value)
 
 	// Skip SNMP error strings gracefully - device doesn't support this OID
 	if kind == reflect.String {
 		if isSnmpErrorString(value.(string)) {
+			trace.Note("skipped, SNMP error string")
 			return nil
 		}
 	}
@@ -88,13 +98,17 @@ func (this *SetTimeSeries) Parse(resources ifs.IResources, workSpace map[string]
 	}
 
 	if _propertyId != nil {
-		modifiedPropertyId := injectIndexOrKey(propertyId, workSpace)
+		modifiedPropertyId := InjectIndexOrKey(propertyId, workSpace)
+		trace.Resolved(modifiedPropertyId)
 		instance, err := properties.PropertyOf(modifiedPropertyId, resources)
 		if err != nil {
+			trace.Set(err)
 			return resources.Logger().Error("error parsing instance path", err.Error())
 		}
 		if instance != nil {
+			trace.Coerced(point)
 			_, _, err := instance.Set(any, point)
+			trace.Set(err)
 			if err != nil {
 				return resources.Logger().Error("error setting time series value:", err.Error())
 			}
@@ -17,6 +17,7 @@ package rules
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -61,10 +62,13 @@ func (this *SnmpBgpToVrf) Parse(resources ifs.IResources, workSpace map[string]i
 	}
 
 	bgpInfo := &types2.BgpInfo{}
+	trace := traceOf(workSpace)
 
 	// Extract global BGP params
 	localAs := ospfGetInt64(cmap, ".1.3.6.1.2.1.15.2.0", resources)
+	trace.Raw(localAs)
 	if localAs == 0 {
+		trace.Note("no bgpLocalAs, BGP is not running")
 		return nil // BGP not running on this device
 	}
 
@@ -78,6 +82,11 @@ func (this *SnmpBgpToVrf) Parse(resources ifs.IResources, workSpace map[string]i
 	ensureLogicalVrf(networkDevice)
 	vrf := networkDevice.Logicals["logical-0"].Vrfs[0]
 	vrf.BgpInfo = bgpInfo
+	if propertyId, ok := workSpace[PropertyId].(string); ok {
+		trace.Resolved(propertyId)
+	}
+	trace.Note(strconv.Itoa(len(bgpInfo.Peers)) + " peers")
+	trace.Set(nil)
 
 	return nil
 }
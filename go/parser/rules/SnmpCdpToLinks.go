@@ -0,0 +1,144 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// CISCO-CDP-MIB OIDs.
+const (
+	// CdpMibOid is the ciscoCdpMIBObjects sub-tree walked by the CDP poll.
+	CdpMibOid = ".1.3.6.1.4.1.9.9.23.1"
+	// cdpInterfaceEntryOid is the cdpInterfaceTable entry, indexed by ifIndex.
+	cdpInterfaceEntryOid = ".1.3.6.1.4.1.9.9.23.1.1.1.1"
+	// cdpCacheEntryOid is the cdpCacheTable entry, indexed by ifIndex.cdpCacheDeviceIndex.
+	cdpCacheEntryOid = ".1.3.6.1.4.1.9.9.23.1.2.1.1"
+	// ifNameOid is the IF-MIB ifName column, used for the local port when the walk holds it.
+	ifNameOid = ".1.3.6.1.2.1.31.1.1.1.1"
+)
+
+// cdpCapabilities are the cdpCacheCapabilities bits, bit 0 being the least significant
+// bit of the 32 bit value.
+var cdpCapabilities = []string{"router", "transparentBridge", "sourceRouteBridge", "switch",
+	"host", "igmp", "repeater", "phone", "remotelyManaged", "cvta", "macRelay"}
+
+// SnmpCdpToLinks is a bulk parsing rule that transforms a CISCO-CDP-MIB (1.3.6.1.4.1.9.9.23.1)
+// walk into NetworkLink entries on the NetworkDevice model, one per cdpCacheTable row. The
+// remote chassis ID is the CDP device ID, and the remote system name is cdpCacheSysName,
+// or the device ID when the device does not report it. The local port is named from
+// cdpInterfaceTable. Fields are set as in SnmpLldpToLinks.
+type SnmpCdpToLinks struct{}
+
+// Name returns the rule identifier "SnmpCdpToLinks".
+func (this *SnmpCdpToLinks) Name() string {
+	return "SnmpCdpToLinks"
+}
+
+// ParamNames returns the required parameter names for this rule.
+func (this *SnmpCdpToLinks) ParamNames() []string {
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SnmpCdpToLinks) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "fields", Type: ParamMappingList, Fields: 2,
+			Help: "Comma-separated neighborField:linkField pairs naming the NetworkLink field of a neighbor field"},
+	}
+}
+
+// Parse executes the SnmpCdpToLinks rule, adding a NetworkLink per CDP neighbor.
+func (this *SnmpCdpToLinks) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	input := workSpace[Input]
+	if input == nil {
+		return errors.New("SnmpCdpToLinks: no input data")
+	}
+	cmap, ok := input.(*l8tpollaris.CMap)
+	if !ok {
+		return errors.New("SnmpCdpToLinks: input is not a CMap")
+	}
+	if len(cmap.Data) == 0 {
+		return nil // CDP is not enabled on this device
+	}
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("SnmpCdpToLinks: ", err.Error())
+	}
+	values := decodeCMap(cmap, resources, cdpInterfaceEntryOid, cdpCacheEntryOid, ifNameOid)
+	return setNeighbors(resources, this.Name(), workSpace, any, CdpNeighbors(values), typed.Mappings("fields"))
+}
+
+// CdpNeighbors returns the neighbors of the cdpCacheTable rows of a CISCO-CDP-MIB walk,
+// keyed by OID, ordered by local ifIndex.
+func CdpNeighbors(values map[string]interface{}) []*Neighbor {
+	deviceIds := tableColumn(values, cdpCacheEntryOid+".6")
+	devicePorts := tableColumn(values, cdpCacheEntryOid+".7")
+	capabilities := tableColumn(values, cdpCacheEntryOid+".9")
+	sysNames := tableColumn(values, cdpCacheEntryOid+".17")
+	interfaceNames := tableColumn(values, cdpInterfaceEntryOid+".6")
+	ifNames := tableColumn(values, ifNameOid)
+
+	indexes := make([]string, 0, len(deviceIds))
+	for index := range deviceIds {
+		if len(indexArcs(index)) == 2 {
+			indexes = append(indexes, index)
+		}
+	}
+	sortIndexes(indexes)
+
+	neighbors := make([]*Neighbor, 0, len(indexes))
+	for _, index := range indexes {
+		ifIndex := strconv.Itoa(indexArcs(index)[0])
+		neighbor := &Neighbor{Protocol: "cdp"}
+		neighbor.LocalPort = displayString(interfaceNames[ifIndex])
+		if neighbor.LocalPort == "" {
+			neighbor.LocalPort = displayString(ifNames[ifIndex])
+		}
+		if neighbor.LocalPort == "" {
+			neighbor.LocalPort = "ifIndex " + ifIndex
+		}
+		neighbor.RemoteChassisId = displayString(deviceIds[index])
+		neighbor.RemotePort = displayString(devicePorts[index])
+		neighbor.RemoteSystemName = displayString(sysNames[index])
+		if neighbor.RemoteSystemName == "" {
+			neighbor.RemoteSystemName = neighbor.RemoteChassisId
+		}
+		neighbor.Capabilities = cdpCapabilityNames(bitsOctets(capabilities[index]))
+		neighbors = append(neighbors, neighbor)
+	}
+	return neighbors
+}
+
+// cdpCapabilityNames returns the names of the bits set in a cdpCacheCapabilities value,
+// the octets of a big endian 32 bit value.
+func cdpCapabilityNames(bits []byte) []string {
+	var mask uint64
+	for _, b := range bits {
+		mask = mask<<8 | uint64(b)
+	}
+	result := make([]string, 0)
+	for i, name := range cdpCapabilities {
+		if mask&(1<<uint(i)) != 0 {
+			result = append(result, name)
+		}
+	}
+	return result
+}
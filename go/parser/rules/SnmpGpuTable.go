@@ -48,7 +48,17 @@ func (this *SnmpGpuTable) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *SnmpGpuTable) ParamNames() []string {
-	return []string{"oid_base", "mapping"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SnmpGpuTable) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "oid_base", Type: ParamOID, Required: true, Help: "Base OID prefix of the GPU table"},
+		{Name: "mapping", Type: ParamMappingList, Required: true, Fields: 3,
+			Help: "Comma-separated oidSuffix:propertyName:type triples, type is set or ts"},
+		{Name: "key_oid", Type: ParamInt, Default: "4", Help: "OID suffix holding the PCI Bus ID map key"},
+	}
 }
 
 // gpuFieldMapping holds a parsed mapping entry.
@@ -83,20 +93,19 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 	}
 
 	// Parse parameters
-	oidBaseParam := params["oid_base"]
-	mappingParam := params["mapping"]
-	if oidBaseParam == nil || mappingParam == nil {
-		return resources.Logger().Error("SnmpGpuTable: missing oid_base or mapping parameter")
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("SnmpGpuTable: ", err.Error())
 	}
 
-	oidBase := oidBaseParam.Value
+	oidBase := typed.String("oid_base")
 	if !strings.HasPrefix(oidBase, ".") {
 		oidBase = "." + oidBase
 	}
 
-	mappings := parseMappings(mappingParam.Value)
+	mappings := parseMappings(typed.Mappings("mapping"))
 	if len(mappings) == 0 {
-		return resources.Logger().Error("SnmpGpuTable: no valid mappings parsed from: ", mappingParam.Value)
+		return resources.Logger().Error("SnmpGpuTable: no valid mappings parsed from: ", params["mapping"].Value)
 	}
 
 	suffixMap := make(map[int]*gpuFieldMapping)
@@ -105,12 +114,7 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 	}
 
 	// Key OID suffix for map key (default 4 = pcibusid)
-	keyOidSuffix := 4
-	if kp := params["key_oid"]; kp != nil {
-		if v, err := strconv.Atoi(kp.Value); err == nil {
-			keyOidSuffix = v
-		}
-	}
+	keyOidSuffix := typed.Int("key_oid")
 
 	var stamp int64
 	if ended, ok := workSpace[JobEnded]; ok {
@@ -162,6 +166,10 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 		entries = append(entries, oidEntry{gpuIndex, metricId, value})
 	}
 
+	trace := traceOf(workSpace)
+	trace.Raw(strconv.Itoa(len(entries)) + " values under " + oidBase)
+	trace.Resolved(propertyId)
+
 	// Pass 1: collect PCI Bus IDs per GPU index
 	gpuKeys := make(map[int]string)
 	for _, e := range entries {
@@ -171,6 +179,7 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 				if isValidPciBusId(candidate) {
 					gpuKeys[e.gpuIndex] = candidate
 				} else {
+					trace.Note("GPU index " + strconv.Itoa(e.gpuIndex) + " skipped, invalid PCI bus ID " + candidate)
 					resources.Logger().Error("SnmpGpuTable: invalid PCI Bus ID '", candidate, "' for GPU index ", fmt.Sprintf("%d", e.gpuIndex), ", skipping")
 				}
 			}
@@ -205,19 +214,23 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 			point := &l8api.L8TimeSeriesPoint{Stamp: stamp, Value: floatVal}
 			instance, err := properties.PropertyOf(fullPropertyId, resources)
 			if err != nil || instance == nil {
+				trace.SetEach(fullPropertyId, propertyError(err))
 				continue
 			}
 			_, _, err = instance.Set(any, point)
+			trace.SetEach(fullPropertyId, err)
 			if err != nil {
 				resources.Logger().Error("SnmpGpuTable: error setting time series for GPU ", mapKey, ":", err.Error())
 			}
 		} else {
 			instance, err := properties.PropertyOf(fullPropertyId, resources)
 			if err != nil || instance == nil {
+				trace.SetEach(fullPropertyId, propertyError(err))
 				continue
 			}
 			e.value = coerceValue(resources, e.value, instance, workSpace)
 			_, _, err = instance.Set(any, e.value)
+			trace.SetEach(fullPropertyId, err)
 			if err != nil {
 				resources.Logger().Error("SnmpGpuTable: error setting value for GPU ", mapKey, ":", err.Error())
 			}
@@ -227,14 +240,12 @@ func (this *SnmpGpuTable) Parse(resources ifs.IResources, workSpace map[string]i
 	return nil
 }
 
-// parseMappings parses a comma-separated mapping string into gpuFieldMapping entries.
-// Format: "oidSuffix:propertyName:type,oidSuffix:propertyName:type,..."
-// type is "set" for static or "ts" for time series.
-func parseMappings(mappingStr string) []gpuFieldMapping {
+// parseMappings converts the parsed "mapping" entries into gpuFieldMapping entries.
+// Each entry is an oidSuffix, propertyName, type triple where type is "set"
+// for static or "ts" for time series.
+func parseMappings(entries [][]string) []gpuFieldMapping {
 	result := make([]gpuFieldMapping, 0)
-	entries := strings.Split(mappingStr, ",")
-	for _, entry := range entries {
-		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+	for _, parts := range entries {
 		if len(parts) != 3 {
 			continue
 		}
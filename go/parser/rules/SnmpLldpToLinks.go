@@ -0,0 +1,182 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// LLDP-MIB (IEEE 802.1AB) OIDs.
+const (
+	// LldpMibOid is the lldpObjects sub-tree walked by the LLDP poll.
+	LldpMibOid = ".1.0.8802.1.1.2.1"
+	// lldpLocPortEntryOid is the lldpLocPortTable entry, indexed by lldpLocPortNum.
+	lldpLocPortEntryOid = ".1.0.8802.1.1.2.1.3.7.1"
+	// lldpRemEntryOid is the lldpRemTable entry, indexed by
+	// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex.
+	lldpRemEntryOid = ".1.0.8802.1.1.2.1.4.1.1"
+)
+
+// lldpCapabilities are the LldpSystemCapabilitiesMap bits, bit 0 being the most
+// significant bit of the first octet.
+var lldpCapabilities = []string{"other", "repeater", "bridge", "wlanAccessPoint", "router",
+	"telephone", "docsisCableDevice", "stationOnly"}
+
+// LLDP chassis and port ID subtypes needing formatting.
+const (
+	lldpChassisNetworkAddress = 5
+	lldpPortMacAddress        = 3
+)
+
+// SnmpLldpToLinks is a bulk parsing rule that transforms an LLDP-MIB (1.0.8802.1.1.2.1)
+// walk into NetworkLink entries on the NetworkDevice model, one per lldpRemTable row, with
+// the local port, remote chassis ID, remote port, remote system name and enabled
+// capabilities. The local port is named from lldpLocPortTable. The NetworkLink fields are
+// resolved by name, see SetNeighborLinks; the "fields" param overrides them.
+type SnmpLldpToLinks struct{}
+
+// Name returns the rule identifier "SnmpLldpToLinks".
+func (this *SnmpLldpToLinks) Name() string {
+	return "SnmpLldpToLinks"
+}
+
+// ParamNames returns the required parameter names for this rule.
+func (this *SnmpLldpToLinks) ParamNames() []string {
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SnmpLldpToLinks) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "fields", Type: ParamMappingList, Fields: 2,
+			Help: "Comma-separated neighborField:linkField pairs naming the NetworkLink field of a neighbor field"},
+	}
+}
+
+// Parse executes the SnmpLldpToLinks rule, adding a NetworkLink per LLDP neighbor.
+func (this *SnmpLldpToLinks) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	input := workSpace[Input]
+	if input == nil {
+		return errors.New("SnmpLldpToLinks: no input data")
+	}
+	cmap, ok := input.(*l8tpollaris.CMap)
+	if !ok {
+		return errors.New("SnmpLldpToLinks: input is not a CMap")
+	}
+	if len(cmap.Data) == 0 {
+		return nil // LLDP is not enabled on this device
+	}
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("SnmpLldpToLinks: ", err.Error())
+	}
+	values := decodeCMap(cmap, resources, lldpLocPortEntryOid, lldpRemEntryOid)
+	return setNeighbors(resources, this.Name(), workSpace, any, LldpNeighbors(values), typed.Mappings("fields"))
+}
+
+// LldpNeighbors returns the neighbors of the lldpRemTable rows of an LLDP-MIB walk, keyed
+// by OID, ordered by local port. When a neighbor has rows of several time marks, the
+// latest one is used.
+func LldpNeighbors(values map[string]interface{}) []*Neighbor {
+	chassisIds := tableColumn(values, lldpRemEntryOid+".5")
+	chassisSubtypes := tableColumn(values, lldpRemEntryOid+".4")
+	portSubtypes := tableColumn(values, lldpRemEntryOid+".6")
+	portIds := tableColumn(values, lldpRemEntryOid+".7")
+	portDescs := tableColumn(values, lldpRemEntryOid+".8")
+	sysNames := tableColumn(values, lldpRemEntryOid+".9")
+	capabilities := tableColumn(values, lldpRemEntryOid+".12")
+	localPortIds := tableColumn(values, lldpLocPortEntryOid+".3")
+	localPortSubtypes := tableColumn(values, lldpLocPortEntryOid+".2")
+	localPortDescs := tableColumn(values, lldpLocPortEntryOid+".4")
+
+	// the latest time mark of each lldpRemLocalPortNum.lldpRemIndex
+	latest := make(map[string]string)
+	for index := range chassisIds {
+		arcs := indexArcs(index)
+		if len(arcs) != 3 {
+			continue
+		}
+		key := strconv.Itoa(arcs[1]) + "." + strconv.Itoa(arcs[2])
+		if current, ok := latest[key]; !ok || indexArcs(current)[0] < arcs[0] {
+			latest[key] = index
+		}
+	}
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sortIndexes(keys)
+
+	neighbors := make([]*Neighbor, 0, len(keys))
+	for _, key := range keys {
+		index := latest[key]
+		localPortNum := strconv.Itoa(indexArcs(index)[1])
+		neighbor := &Neighbor{Protocol: "lldp"}
+		neighbor.LocalPort = lldpPortName(localPortIds[localPortNum], localPortSubtypes[localPortNum], localPortDescs[localPortNum])
+		if neighbor.LocalPort == "" {
+			neighbor.LocalPort = "port " + localPortNum
+		}
+		neighbor.RemoteChassisId = lldpChassisId(chassisIds[index], chassisSubtypes[index])
+		neighbor.RemotePort = lldpPortName(portIds[index], portSubtypes[index], portDescs[index])
+		neighbor.RemoteSystemName = displayString(sysNames[index])
+		neighbor.Capabilities = capabilityNames(bitsOctets(capabilities[index]), lldpCapabilities)
+		neighbors = append(neighbors, neighbor)
+	}
+	return neighbors
+}
+
+// lldpChassisId formats a chassis ID, a network address chassis ID as its IP address.
+func lldpChassisId(value, subtype interface{}) string {
+	b := octets(value)
+	if n, _ := toInt64(subtype); n == lldpChassisNetworkAddress && b != nil {
+		// the IANA address family octet followed by the address, 1 is IPv4 and 2 IPv6
+		if (len(b) == 5 && b[0] == 1) || (len(b) == 17 && b[0] == 2) {
+			return net.IP(b[1:]).String()
+		}
+	}
+	return displayString(value)
+}
+
+// lldpPortName returns the port ID, or the port description when the port ID is a MAC
+// address and the description is set.
+func lldpPortName(id, subtype, desc interface{}) string {
+	if n, _ := toInt64(subtype); n == lldpPortMacAddress {
+		if name := displayString(desc); name != "" {
+			return name
+		}
+	}
+	if name := displayString(id); name != "" {
+		return name
+	}
+	return displayString(desc)
+}
+
+// capabilityNames returns the names of the bits set in a BITS value, bit 0 being the
+// most significant bit of the first octet.
+func capabilityNames(bits []byte, names []string) []string {
+	result := make([]string, 0)
+	for i, name := range names {
+		if i/8 < len(bits) && bits[i/8]&(0x80>>uint(i%8)) != 0 {
+			result = append(result, name)
+		}
+	}
+	return result
+}
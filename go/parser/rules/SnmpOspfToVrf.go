@@ -18,6 +18,7 @@ package rules
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -63,10 +64,13 @@ func (this *SnmpOspfToVrf) Parse(resources ifs.IResources, workSpace map[string]
 	}
 
 	ospfInfo := &types2.OspfInfo{}
+	trace := traceOf(workSpace)
 
 	// Extract general OSPF params (1.3.6.1.2.1.14.1.*)
 	routerId := ospfGetString(cmap, ".1.3.6.1.2.1.14.1.1.0", resources)
+	trace.Raw(routerId)
 	if routerId == "" {
+		trace.Note("no ospfRouterId, OSPF is not running")
 		return nil // OSPF not running on this device
 	}
 
@@ -91,6 +95,11 @@ func (this *SnmpOspfToVrf) Parse(resources ifs.IResources, workSpace map[string]
 	ensureLogicalVrf(networkDevice)
 	vrf := networkDevice.Logicals["logical-0"].Vrfs[0]
 	vrf.OspfInfo = ospfInfo
+	if propertyId, ok := workSpace[PropertyId].(string); ok {
+		trace.Resolved(propertyId)
+	}
+	trace.Note("area " + ospfInfo.AreaId + ", " + strconv.Itoa(len(ospfInfo.Neighbors)) + " neighbors")
+	trace.Set(nil)
 
 	return nil
 }
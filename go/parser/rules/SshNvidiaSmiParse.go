@@ -39,7 +39,15 @@ func (this *SshNvidiaSmiParse) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *SshNvidiaSmiParse) ParamNames() []string {
-	return []string{"format"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SshNvidiaSmiParse) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "format", Type: ParamEnum, Required: true, Help: "Command output format",
+			EnumValues: []string{"utilization", "temperature", "power", "version", "lscpu"}},
+	}
 }
 
 // Parse executes the SshNvidiaSmiParse rule, dispatching to the appropriate format parser.
@@ -62,6 +70,7 @@ func (this *SshNvidiaSmiParse) Parse(resources ifs.IResources, workSpace map[str
 	}
 
 	if strings.TrimSpace(sshOutput) == "" {
+		traceOf(workSpace).Note("empty SSH output")
 		resources.Logger().Error("SshNvidiaSmiParse: empty SSH output for ", pollWhat)
 		return nil
 	}
@@ -82,18 +91,21 @@ func (this *SshNvidiaSmiParse) Parse(resources ifs.IResources, workSpace map[str
 			stamp = s
 		}
 	}
+	trace := traceOf(workSpace)
+	trace.Raw(sshOutput)
+	trace.Resolved(propertyId)
 
 	switch formatParam.Value {
 	case "utilization":
-		return parseNvidiaSmiUtilization(resources, sshOutput, propertyId, stamp, any)
+		return parseNvidiaSmiUtilization(resources, trace, sshOutput, propertyId, stamp, any)
 	case "temperature":
-		return parseNvidiaSmiTemperature(resources, sshOutput, propertyId, stamp, any)
+		return parseNvidiaSmiTemperature(resources, trace, sshOutput, propertyId, stamp, any)
 	case "power":
-		return parseNvidiaSmiPower(resources, sshOutput, propertyId, any)
+		return parseNvidiaSmiPower(resources, trace, sshOutput, propertyId, any)
 	case "version":
-		return parseShowVersion(resources, sshOutput, propertyId, any)
+		return parseShowVersion(resources, trace, sshOutput, propertyId, any)
 	case "lscpu":
-		return parseLscpu(resources, sshOutput, propertyId, any)
+		return parseLscpu(resources, trace, sshOutput, propertyId, any)
 	default:
 		return errors.New("SshNvidiaSmiParse: unknown format: " + formatParam.Value)
 	}
@@ -101,7 +113,7 @@ func (this *SshNvidiaSmiParse) Parse(resources ifs.IResources, workSpace map[str
 
 // parseNvidiaSmiUtilization parses "nvidia-smi -q -d UTILIZATION" output.
 // Extracts encoder and decoder utilization per GPU.
-func parseNvidiaSmiUtilization(resources ifs.IResources, output, propertyId string, stamp int64, any interface{}) error {
+func parseNvidiaSmiUtilization(resources ifs.IResources, trace *RuleTrace, output, propertyId string, stamp int64, any interface{}) error {
 	gpuKey := ""
 	lines := strings.Split(output, "\n")
 
@@ -111,7 +123,7 @@ func parseNvidiaSmiUtilization(resources ifs.IResources, output, propertyId stri
 		if strings.HasPrefix(trimmed, "GPU ") && strings.Contains(trimmed, ":") {
 			gpuKey = extractGpuPciBusId(trimmed)
 			if gpuKey != "" {
-				setGpuProperty(resources, propertyId, gpuKey, "pcibusid", gpuKey, any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "pcibusid", gpuKey, any)
 			}
 			continue
 		}
@@ -123,13 +135,13 @@ func parseNvidiaSmiUtilization(resources ifs.IResources, output, propertyId stri
 		if strings.HasPrefix(trimmed, "Encoder") && strings.Contains(trimmed, ":") {
 			val := extractPercentValue(trimmed)
 			if val >= 0 {
-				setGpuTimeSeries(resources, propertyId, gpuKey, "encoderutilizationpercent", stamp, val, any)
+				setGpuTimeSeries(resources, trace, propertyId, gpuKey, "encoderutilizationpercent", stamp, val, any)
 			}
 		}
 		if strings.HasPrefix(trimmed, "Decoder") && strings.Contains(trimmed, ":") {
 			val := extractPercentValue(trimmed)
 			if val >= 0 {
-				setGpuTimeSeries(resources, propertyId, gpuKey, "decoderutilizationpercent", stamp, val, any)
+				setGpuTimeSeries(resources, trace, propertyId, gpuKey, "decoderutilizationpercent", stamp, val, any)
 			}
 		}
 	}
@@ -138,7 +150,7 @@ func parseNvidiaSmiUtilization(resources ifs.IResources, output, propertyId stri
 
 // parseNvidiaSmiTemperature parses "nvidia-smi -q -d TEMPERATURE" output.
 // Extracts memory temperature, shutdown temp, and slowdown temp per GPU.
-func parseNvidiaSmiTemperature(resources ifs.IResources, output, propertyId string, stamp int64, any interface{}) error {
+func parseNvidiaSmiTemperature(resources ifs.IResources, trace *RuleTrace, output, propertyId string, stamp int64, any interface{}) error {
 	gpuKey := ""
 	lines := strings.Split(output, "\n")
 
@@ -148,7 +160,7 @@ func parseNvidiaSmiTemperature(resources ifs.IResources, output, propertyId stri
 		if strings.HasPrefix(trimmed, "GPU ") && strings.Contains(trimmed, ":") {
 			gpuKey = extractGpuPciBusId(trimmed)
 			if gpuKey != "" {
-				setGpuProperty(resources, propertyId, gpuKey, "pcibusid", gpuKey, any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "pcibusid", gpuKey, any)
 			}
 			continue
 		}
@@ -160,19 +172,19 @@ func parseNvidiaSmiTemperature(resources ifs.IResources, output, propertyId stri
 		if strings.Contains(trimmed, "GPU Memory Temp") || strings.Contains(trimmed, "Memory Current Temp") {
 			val := extractTempValue(trimmed)
 			if val >= 0 {
-				setGpuTimeSeries(resources, propertyId, gpuKey, "memorytemperaturecelsius", stamp, val, any)
+				setGpuTimeSeries(resources, trace, propertyId, gpuKey, "memorytemperaturecelsius", stamp, val, any)
 			}
 		}
 		if strings.Contains(trimmed, "GPU Shutdown Temp") {
 			val := extractTempValue(trimmed)
 			if val >= 0 {
-				setGpuProperty(resources, propertyId, gpuKey, "shutdowntemperature", float64(val), any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "shutdowntemperature", float64(val), any)
 			}
 		}
 		if strings.Contains(trimmed, "GPU Slowdown Temp") {
 			val := extractTempValue(trimmed)
 			if val >= 0 {
-				setGpuProperty(resources, propertyId, gpuKey, "slowdowntemperature", float64(val), any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "slowdowntemperature", float64(val), any)
 			}
 		}
 	}
@@ -181,7 +193,7 @@ func parseNvidiaSmiTemperature(resources ifs.IResources, output, propertyId stri
 
 // parseNvidiaSmiPower parses "nvidia-smi -q -d POWER" output.
 // Extracts power limit per GPU.
-func parseNvidiaSmiPower(resources ifs.IResources, output, propertyId string, any interface{}) error {
+func parseNvidiaSmiPower(resources ifs.IResources, trace *RuleTrace, output, propertyId string, any interface{}) error {
 	gpuKey := ""
 	lines := strings.Split(output, "\n")
 
@@ -191,7 +203,7 @@ func parseNvidiaSmiPower(resources ifs.IResources, output, propertyId string, an
 		if strings.HasPrefix(trimmed, "GPU ") && strings.Contains(trimmed, ":") {
 			gpuKey = extractGpuPciBusId(trimmed)
 			if gpuKey != "" {
-				setGpuProperty(resources, propertyId, gpuKey, "pcibusid", gpuKey, any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "pcibusid", gpuKey, any)
 			}
 			continue
 		}
@@ -206,7 +218,7 @@ func parseNvidiaSmiPower(resources ifs.IResources, output, propertyId string, an
 			}
 			val := extractWattValue(trimmed)
 			if val >= 0 {
-				setGpuProperty(resources, propertyId, gpuKey, "powerlimitwatts", val, any)
+				setGpuProperty(resources, trace, propertyId, gpuKey, "powerlimitwatts", val, any)
 			}
 		}
 	}
@@ -214,7 +226,7 @@ func parseNvidiaSmiPower(resources ifs.IResources, output, propertyId string, an
 }
 
 // parseShowVersion parses "show version" output for kernel version, model, and serial number.
-func parseShowVersion(resources ifs.IResources, output, propertyId string, any interface{}) error {
+func parseShowVersion(resources ifs.IResources, trace *RuleTrace, output, propertyId string, any interface{}) error {
 	lines := strings.Split(output, "\n")
 
 	for _, line := range lines {
@@ -228,21 +240,21 @@ func parseShowVersion(resources ifs.IResources, output, propertyId string, any i
 		if strings.Contains(lower, "kernel") && strings.Contains(trimmed, ":") {
 			val := extractKV(trimmed)
 			if val != "" {
-				setProperty(resources, propertyId+".kernelversion", val, any)
+				setProperty(resources, trace, propertyId+".kernelversion", val, any)
 			}
 		}
 
 		if (strings.Contains(lower, "dgx") || strings.Contains(lower, "hgx")) && strings.Contains(lower, "software") {
 			val := extractKV(trimmed)
 			if val != "" {
-				setProperty(resources, propertyId+".model", val, any)
+				setProperty(resources, trace, propertyId+".model", val, any)
 			}
 		}
 
 		if strings.Contains(lower, "serial") && strings.Contains(trimmed, ":") {
 			val := extractKV(trimmed)
 			if val != "" {
-				setProperty(resources, propertyId+".serialnumber", val, any)
+				setProperty(resources, trace, propertyId+".serialnumber", val, any)
 			}
 		}
 	}
@@ -250,7 +262,7 @@ func parseShowVersion(resources ifs.IResources, output, propertyId string, any i
 }
 
 // parseLscpu parses "lscpu" output for CPU sockets and total cores.
-func parseLscpu(resources ifs.IResources, output, propertyId string, any interface{}) error {
+func parseLscpu(resources ifs.IResources, trace *RuleTrace, output, propertyId string, any interface{}) error {
 	lines := strings.Split(output, "\n")
 
 	for _, line := range lines {
@@ -262,14 +274,14 @@ func parseLscpu(resources ifs.IResources, output, propertyId string, any interfa
 		if strings.HasPrefix(trimmed, "Socket(s):") {
 			val := extractKV(trimmed)
 			if n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 32); err == nil {
-				setProperty(resources, propertyId+".cpusockets", uint32(n), any)
+				setProperty(resources, trace, propertyId+".cpusockets", uint32(n), any)
 			}
 		}
 
 		if strings.HasPrefix(trimmed, "CPU(s):") && !strings.Contains(trimmed, "On-line") && !strings.Contains(trimmed, "NUMA") {
 			val := extractKV(trimmed)
 			if n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 32); err == nil {
-				setProperty(resources, propertyId+".cpucorestotal", uint32(n), any)
+				setProperty(resources, trace, propertyId+".cpucorestotal", uint32(n), any)
 			}
 		}
 	}
@@ -346,47 +358,56 @@ func extractKV(line string) string {
 }
 
 // setGpuTimeSeries sets a time series value on a per-GPU property.
-func setGpuTimeSeries(resources ifs.IResources, propertyId string, gpuKey string, field string, stamp int64, value float64, any interface{}) {
+func setGpuTimeSeries(resources ifs.IResources, trace *RuleTrace, propertyId string, gpuKey string, field string, stamp int64, value float64, any interface{}) {
 	fullId := fmt.Sprintf("%s<{24}%s>.%s", propertyId, gpuKey, field)
 	point := &l8api.L8TimeSeriesPoint{Stamp: stamp, Value: value}
 	instance, err := properties.PropertyOf(fullId, resources)
 	if err != nil {
+		trace.SetEach(fullId, err)
 		resources.Logger().Error("setGpuTimeSeries: PropertyOf failed for '", fullId, "': ", err.Error())
 		return
 	}
 	if instance == nil {
+		trace.SetEach(fullId, propertyError(nil))
 		resources.Logger().Error("setGpuTimeSeries: PropertyOf returned nil for '", fullId, "'")
 		return
 	}
-	instance.Set(any, point)
+	_, _, err = instance.Set(any, point)
+	trace.SetEach(fullId, err)
 }
 
 // setGpuProperty sets a static value on a per-GPU property.
-func setGpuProperty(resources ifs.IResources, propertyId string, gpuKey string, field string, value interface{}, any interface{}) {
+func setGpuProperty(resources ifs.IResources, trace *RuleTrace, propertyId string, gpuKey string, field string, value interface{}, any interface{}) {
 	fullId := fmt.Sprintf("%s<{24}%s>.%s", propertyId, gpuKey, field)
 	instance, err := properties.PropertyOf(fullId, resources)
 	if err != nil {
+		trace.SetEach(fullId, err)
 		resources.Logger().Error("setGpuProperty: PropertyOf failed for '", fullId, "': ", err.Error())
 		return
 	}
 	if instance == nil {
+		trace.SetEach(fullId, propertyError(nil))
 		resources.Logger().Error("setGpuProperty: PropertyOf returned nil for '", fullId, "'")
 		return
 	}
-	instance.Set(any, value)
+	_, _, err = instance.Set(any, value)
+	trace.SetEach(fullId, err)
 }
 
 // setProperty sets a value on a direct property path.
-func setProperty(resources ifs.IResources, propertyId string, value interface{}, any interface{}) {
-	modifiedId := injectIndexOrKey(propertyId, nil)
+func setProperty(resources ifs.IResources, trace *RuleTrace, propertyId string, value interface{}, any interface{}) {
+	modifiedId := InjectIndexOrKey(propertyId, nil)
 	instance, err := properties.PropertyOf(modifiedId, resources)
 	if err != nil {
+		trace.SetEach(modifiedId, err)
 		resources.Logger().Error("setProperty: PropertyOf failed for '", modifiedId, "': ", err.Error())
 		return
 	}
 	if instance == nil {
+		trace.SetEach(modifiedId, propertyError(nil))
 		resources.Logger().Error("setProperty: PropertyOf returned nil for '", modifiedId, "'")
 		return
 	}
-	instance.Set(any, value)
+	_, _, err = instance.Set(any, value)
+	trace.SetEach(modifiedId, err)
 }
@@ -18,6 +18,7 @@ package rules
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -37,7 +38,15 @@ func (this *SshVrfParse) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *SshVrfParse) ParamNames() []string {
-	return []string{"format"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SshVrfParse) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: "format", Type: ParamString, Required: true,
+			Help: "Vendor output format: iosxr, ios, nxos, junos, timos, vrp, eos, voss or univerge, anything else uses the generic parser"},
+	}
 }
 
 // Parse executes the SshVrfParse rule, parsing SSH VRF output into VrfInstance structures.
@@ -58,7 +67,10 @@ func (this *SshVrfParse) Parse(resources ifs.IResources, workSpace map[string]in
 		return errors.New("SshVrfParse: input is not a string: " + fmt.Sprintf("%T", input))
 	}
 
+	trace := traceOf(workSpace)
+	trace.Raw(sshOutput)
 	if strings.TrimSpace(sshOutput) == "" {
+		trace.Note("empty SSH output")
 		return nil
 	}
 
@@ -75,12 +87,18 @@ func (this *SshVrfParse) Parse(resources ifs.IResources, workSpace map[string]in
 
 	vrfs := parseVrfOutput(sshOutput, formatParam.Value)
 	if len(vrfs) == 0 {
+		trace.Note("no VRF found in the " + formatParam.Value + " output")
 		return nil
 	}
 
 	// Set VRFs on NetworkDevice
 	ensureLogical(networkDevice)
 	networkDevice.Logicals["logical-0"].Vrfs = vrfs
+	if propertyId, ok := workSpace[PropertyId].(string); ok {
+		trace.Resolved(propertyId)
+	}
+	trace.Note(strconv.Itoa(len(vrfs)) + " VRFs")
+	trace.Set(nil)
 
 	return nil
 }
@@ -16,6 +16,7 @@ limitations under the License.
 package rules
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
@@ -27,7 +28,7 @@ import (
 // StringToCTable is a parsing rule that converts a multi-line string into a structured table (CTable).
 // It parses tabular output (like CLI command output) by detecting columns from the header
 // and extracting values from subsequent rows.
-// Parameters: "columns" (expected number of columns), "keycolumn" (column indices for the key).
+// Parameters: "columns" (expected number of columns), "key_column" (column indices for the key).
 type StringToCTable struct{}
 
 // Name returns the rule identifier "StringToCTable".
@@ -37,26 +38,40 @@ func (this *StringToCTable) Name() string {
 
 // ParamNames returns the required parameter names for this rule.
 func (this *StringToCTable) ParamNames() []string {
-	return []string{"columns", "keycolumn"}
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *StringToCTable) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: Columns, Type: ParamInt, Required: true, Help: "Expected number of columns in the table header"},
+		{Name: KeyColumn, Type: ParamIntList, Help: "Column indices forming the row key, consumed by the chained table rules"},
+	}
 }
 
 // Parse executes the StringToCTable rule, converting a string input to a CTable structure.
 // If the input is already a CTable (e.g., from an SNMP table walk), it passes it through directly.
 func (this *StringToCTable) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	trace := traceOf(workSpace)
 	// If input is already a CTable (from SNMP table walk), pass it through
 	if table, ok := workSpace[Input].(*l8tpollaris.CTable); ok {
+		trace.Raw(strconv.Itoa(len(table.Rows)) + " rows")
+		trace.Note("input is already a CTable, passed through")
 		workSpace[Output] = table
 		return nil
 	}
 
 	input, ok := workSpace[Input].(string)
 	if !ok {
+		trace.Note("skipped, input is neither a string nor a CTable")
 		return nil
 	}
-	colmns, err := getIntInput(workSpace, Columns)
+	trace.Raw(input)
+	typed, err := typedParams(this, workSpace, params)
 	if err != nil {
 		return err
 	}
+	colmns := typed.Int(Columns)
 
 	lines := strings.Split(input, "\n")
 	table := &l8tpollaris.CTable{}
@@ -73,6 +88,7 @@ func (this *StringToCTable) Parse(resources ifs.IResources, workSpace map[string
 		row.Data = getValues(line, table.Columns)
 		table.Rows[int32(i)] = row
 	}
+	trace.Coerced(strconv.Itoa(len(table.Columns)) + " columns, " + strconv.Itoa(len(table.Rows)) + " rows")
 	workSpace[Output] = table
 	return nil
 }
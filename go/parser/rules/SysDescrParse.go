@@ -0,0 +1,389 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// SysDescr fields, the values of the SysDescrParse "field" param.
+const (
+	SysDescrSoftware = "software"
+	SysDescrVersion  = "version"
+	SysDescrModel    = "model"
+	SysDescrSeries   = "series"
+	SysDescrFamily   = "family"
+)
+
+// SysDescr holds what a vendor sysDescr tells about the device.
+type SysDescr struct {
+	// Platform is the name of the parser that recognized the sysDescr, e.g. "cisco-ios-xe".
+	Platform string
+	// Software is the operating system, e.g. "IOS-XE", "JUNOS" or "EOS".
+	Software string
+	Version  string
+	// Model is the product model, e.g. "C2960", "EX4300-48T" or "DCS-7050TX-64".
+	Model string
+	// Series is the product series of the model, e.g. "Catalyst 2960" or "EX4300".
+	Series string
+	// Family is the product family, e.g. "Catalyst", "Nexus" or "EX".
+	Family string
+}
+
+// Field returns the value of a SysDescr field by its SysDescrParse "field" name.
+func (this *SysDescr) Field(name string) string {
+	switch name {
+	case SysDescrSoftware:
+		return this.Software
+	case SysDescrVersion:
+		return this.Version
+	case SysDescrModel:
+		return this.Model
+	case SysDescrSeries:
+		return this.Series
+	case SysDescrFamily:
+		return this.Family
+	}
+	return ""
+}
+
+// sysDescrParser recognizes the sysDescr of one platform and extracts its fields.
+type sysDescrParser struct {
+	platform string
+	software string
+	match    *regexp.Regexp
+	// version and model hold the patterns of the version and model groups, tried in order.
+	version []*regexp.Regexp
+	model   []*regexp.Regexp
+	// series derives the series and family from the model and the whole sysDescr.
+	series func(model, sysDescr string) (string, string)
+}
+
+// sysDescrParsers are tried in order, so the more specific platforms of a vendor,
+// e.g. IOS-XR and IOS-XE, come before the generic one, e.g. IOS.
+var sysDescrParsers = []*sysDescrParser{
+	{
+		platform: "cisco-ios-xr",
+		software: "IOS-XR",
+		match:    regexp.MustCompile(`Cisco IOS[ -]XR`),
+		version:  regexps(`Version (?P<version>\d[\w.]*)`),
+		model:    regexps(`\(Cisco (?P<model>[\w\-]+) Series\)`, `\b(?P<model>(?:ASR|NCS|CRS|XRv)[\w\-]*)`),
+		series:   ciscoSeries,
+	},
+	{
+		platform: "cisco-nx-os",
+		software: "NX-OS",
+		match:    regexp.MustCompile(`Cisco NX-OS`),
+		version:  regexps(`Version (?P<version>\d[\w.()]*[\w)])`),
+		model:    regexps(`NX-OS(?:\(tm\))?\s+(?P<model>[nN]\d+\w*)`),
+		series:   ciscoSeries,
+	},
+	{
+		platform: "cisco-ios-xe",
+		software: "IOS-XE",
+		match:    regexp.MustCompile(`IOS[ -]XE|IOSXE|_LINUX_IOSD`),
+		version:  regexps(`Version (?P<version>\d[\w.()]*[\w)])`),
+		// the ISR 1000 and 4000 only name their family, e.g. "ISR Software (X86_64_LINUX_IOSD-..."
+		model: regexps(`,\s*(?P<model>[\w\-]*\d[\w\-]*) Software \(`, `\((?P<model>CAT\d+K)_`,
+			`\((?P<model>cat\d+\w*)-`, `,\s*(?P<model>ISR) Software \(`),
+		series: ciscoSeries,
+	},
+	{
+		platform: "cisco-ios",
+		software: "IOS",
+		match:    regexp.MustCompile(`Cisco IOS Software|Cisco Internetwork Operating System`),
+		version:  regexps(`Version (?P<version>\d[\w.()]*[\w)])`),
+		model:    regexps(`,\s*(?P<model>[\w\-]*\d[\w\-]*) Software \(`),
+		series:   ciscoSeries,
+	},
+	{
+		platform: "juniper-junos",
+		software: "JUNOS",
+		match:    regexp.MustCompile(`(?i)\bJUNOS\b`),
+		version:  regexps(`(?i)JUNOS (?P<version>\d[\w.\-]*[\w])`),
+		model:    regexps(`Juniper Networks, Inc\.\s+(?P<model>[\w\-]+)`),
+		series:   prefixSeries,
+	},
+	{
+		platform: "arista-eos",
+		software: "EOS",
+		match:    regexp.MustCompile(`Arista Networks EOS`),
+		version:  regexps(`(?i)EOS version (?P<version>\d[\w.\-]*[\w])`),
+		model:    regexps(`running on an? (?:Arista Networks )?(?P<model>[\w\-]+)`),
+		series:   prefixSeries,
+	},
+	{
+		platform: "paloalto-pan-os",
+		software: "PAN-OS",
+		match:    regexp.MustCompile(`Palo Alto Networks`),
+		version:  regexps(`(?i)(?:PAN-OS|version) (?P<version>\d[\w.\-]*[\w])`),
+		model:    regexps(`\b(?P<model>PA-[\w\-]+)`),
+		series:   prefixSeries,
+	},
+	{
+		platform: "fortinet-fortios",
+		software: "FortiOS",
+		match:    regexp.MustCompile(`Forti(?:Gate|OS|Wifi|Switch)`),
+		version:  regexps(`\bv(?P<version>\d+\.\d+[\w.]*)`, `(?i)FortiOS (?P<version>\d[\w.]*)`),
+		model:    regexps(`\b(?P<model>Forti(?:Gate|Wifi|Switch)-[\w\-]+)`),
+		series:   prefixSeries,
+	},
+	{
+		platform: "nokia-timos",
+		software: "TiMOS",
+		match:    regexp.MustCompile(`TiMOS-`),
+		version:  regexps(`TiMOS-\w-(?P<version>[\w.\-]+)`),
+		model:    regexps(`(?:Nokia|ALCATEL|Alcatel-Lucent)\s+(?P<model>\d+\s+[A-Z]+(?:-\w+)?)`),
+		series:   nokiaSeries,
+	},
+	{
+		platform: "huawei-vrp",
+		software: "VRP",
+		match:    regexp.MustCompile(`Huawei Versatile Routing Platform|\bVRP\b`),
+		version:  regexps(`\b(?P<version>V\d{3}R\d{3}\w*)`, `Version (?P<version>\d[\w.]*)`),
+		model:    regexps(`\((?P<model>[\w\-]+)\s+V\d{3}R\d{3}`, `(?i)HUAWEI (?P<model>[A-Z]+\d+[\w\-]*)`),
+		series:   prefixSeries,
+	},
+	{
+		platform: "linux",
+		software: "Linux",
+		match:    regexp.MustCompile(`^Linux\b`),
+		version:  regexps(`^Linux \S+ (?P<version>\d[\w.\-+]*)`),
+		series:   linuxFamily,
+	},
+}
+
+// ParseSysDescr parses the sysDescr of the supported platforms: Cisco IOS, IOS-XE, NX-OS
+// and IOS-XR, Juniper JUNOS, Arista EOS, Palo Alto PAN-OS, Fortinet FortiOS, Nokia TiMOS,
+// Huawei VRP and Linux. It returns nil when the platform is not recognized.
+func ParseSysDescr(sysDescr string) *SysDescr {
+	sysDescr = strings.TrimSpace(sysDescr)
+	for _, parser := range sysDescrParsers {
+		if !parser.match.MatchString(sysDescr) {
+			continue
+		}
+		result := &SysDescr{Platform: parser.platform, Software: parser.software}
+		result.Version = firstGroup(sysDescr, parser.version)
+		result.Model = firstGroup(sysDescr, parser.model)
+		if strings.ToLower(result.Model) == result.Model {
+			// e.g. "ex4300-48t" of JUNOS and "n9000" of NX-OS
+			result.Model = strings.ToUpper(result.Model)
+		}
+		if parser.series != nil {
+			result.Series, result.Family = parser.series(result.Model, sysDescr)
+		}
+		return result
+	}
+	return nil
+}
+
+// SysDescrParse is a parsing rule that sets one field of the vendor aware sysDescr
+// parse (see ParseSysDescr) on the attribute's property, e.g. "version" on
+// networkdevice.equipmentinfo.version. Nothing is set when the platform is not
+// recognized or the sysDescr does not hold the field, so it can follow a generic rule
+// such as RegexExtract on the same attribute and only refine its value.
+type SysDescrParse struct{}
+
+// Name returns the rule identifier "SysDescrParse".
+func (this *SysDescrParse) Name() string {
+	return "SysDescrParse"
+}
+
+// ParamNames returns the required parameter names for this rule.
+func (this *SysDescrParse) ParamNames() []string {
+	return RequiredParamNames(this.ParamSchema())
+}
+
+// ParamSchema describes the typed parameters of this rule.
+func (this *SysDescrParse) ParamSchema() []*ParamSpec {
+	return []*ParamSpec{
+		{Name: From, Type: ParamString, Help: "Input map key holding sysDescr, required for map input"},
+		{Name: "field", Type: ParamEnum, Required: true, Help: "Parsed field set on the property",
+			EnumValues: []string{SysDescrSoftware, SysDescrVersion, SysDescrModel, SysDescrSeries, SysDescrFamily}},
+	}
+}
+
+// Parse executes the SysDescrParse rule logic.
+func (this *SysDescrParse) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	input := workSpace[Input]
+	if input == nil {
+		return resources.Logger().Error("nil input for job")
+	}
+	typed, err := typedParams(this, workSpace, params)
+	if err != nil {
+		return resources.Logger().Error("SysDescrParse: ", err.Error())
+	}
+
+	trace := traceOf(workSpace)
+	value, kind, err := GetValueInput(resources, input, params, pollWhat)
+	if err != nil || value == nil {
+		if err != nil {
+			trace.Note("skipped, no value: " + err.Error())
+		}
+		return nil
+	}
+	sysDescr, err := convertToString(value, kind)
+	if err != nil {
+		return resources.Logger().Error("SysDescrParse: ", err.Error())
+	}
+	trace.Raw(sysDescr)
+
+	parsed := ParseSysDescr(sysDescr)
+	if parsed == nil {
+		trace.Note("platform not recognized")
+		return nil
+	}
+	fieldValue := parsed.Field(typed.String("field"))
+	if fieldValue == "" {
+		trace.Note("no " + typed.String("field") + " in " + parsed.Platform + " sysDescr")
+		return nil
+	}
+
+	_propertyId := workSpace[PropertyId]
+	if _propertyId != nil {
+		propertyId := InjectIndexOrKey(_propertyId.(string), workSpace)
+		trace.Resolved(propertyId)
+		trace.Coerced(fieldValue)
+		instance, err := properties.PropertyOf(propertyId, resources)
+		if err != nil {
+			trace.Set(err)
+			return resources.Logger().Error("SysDescrParse: error resolving property:", err.Error())
+		}
+		if instance != nil {
+			_, _, err = instance.Set(any, fieldValue)
+			trace.Set(err)
+			if err != nil {
+				return resources.Logger().Error("SysDescrParse: error setting property:", err.Error())
+			}
+		}
+	}
+	workSpace[Output] = fieldValue
+	return nil
+}
+
+func regexps(patterns ...string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		result = append(result, regexp.MustCompile(pattern))
+	}
+	return result
+}
+
+// firstGroup returns the first named group of the first pattern matching text.
+func firstGroup(text string, patterns []*regexp.Regexp) string {
+	for _, re := range patterns {
+		match := re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			if name != "" && strings.TrimSpace(match[i]) != "" {
+				return strings.TrimSpace(match[i])
+			}
+		}
+	}
+	return ""
+}
+
+// modelPrefix splits a model into its leading letters, separator and first number,
+// e.g. "DCS-7050TX-64" into "DCS", "-" and "7050".
+var modelPrefix = regexp.MustCompile(`^([A-Za-z]+)([\-\s]?)(\d+K?)`)
+
+// prefixSeries derives the family from the letters of the model and the series from
+// the letters and first number, e.g. "EX" and "EX4300" for "EX4300-48T".
+func prefixSeries(model, sysDescr string) (string, string) {
+	match := modelPrefix.FindStringSubmatch(model)
+	if match == nil {
+		return "", ""
+	}
+	return match[1] + match[2] + match[3], match[1]
+}
+
+// ciscoFamilies map the Cisco models, e.g. "WS-C2960X-48TS-L" or the "C2900" image family
+// of IOS, to their product family and series, tried in order. The series is the expansion
+// of the match, empty when the model only names the family. Bare "C" models are routers
+// or switches depending on their number, so only the known numbers of each are matched,
+// the switches first as e.g. "C2960" would otherwise be taken for an ISR 2900.
+var ciscoFamilies = []struct {
+	model  *regexp.Regexp
+	family string
+	series string
+}{
+	{regexp.MustCompile(`^WS-C(\d+K?)`), "Catalyst", "Catalyst ${1}"},
+	{regexp.MustCompile(`^CAT(\d+K?)`), "Catalyst", "Catalyst ${1}"},
+	{regexp.MustCompile(`^ISR(\d)\d*`), "ISR", "ISR ${1}000"},
+	{regexp.MustCompile(`^ISR$`), "ISR", ""},
+	// the Catalyst switches among the bare "C" models, e.g. "C2960" and "C3850"
+	{regexp.MustCompile(`^C(1000|29[4-7]0|29[56]5|3[56]50|3[5-8]60|3750|3850|45\d\d|65\d\d|68\d\d|9[2-6]00)`),
+		"Catalyst", "Catalyst ${1}"},
+	// ISR G1 and G2, e.g. "C181X" and "C2900"
+	{regexp.MustCompile(`^C(18|19|28|29|38|39)(?:\d\d|\dX)`), "ISR", "ISR ${1}00"},
+	// ISR 800, e.g. "C880DATA", not the 4 digit Catalyst 8000 edge platforms
+	{regexp.MustCompile(`^C8\d\d(?:\D|$)`), "ISR", "ISR 800"},
+	{regexp.MustCompile(`^C11\d\d`), "ISR", "ISR 1100"},
+	{regexp.MustCompile(`^ASR[\-\s]?(\d+K?)`), "ASR", "ASR ${1}"},
+	{regexp.MustCompile(`^CSR[\-\s]?(\d+V?)`), "CSR", "CSR ${1}"},
+	{regexp.MustCompile(`^NCS[\-\s]?(\d+K?)`), "NCS", "NCS ${1}"},
+	{regexp.MustCompile(`^CRS[\-\s]?(\d+)`), "CRS", "CRS ${1}"},
+	{regexp.MustCompile(`^XRV(\d*)`), "XRv", "XRv ${1}"},
+	{regexp.MustCompile(`^IE[\-\s]?(\d+)`), "IE", "IE ${1}"},
+	{regexp.MustCompile(`^N(\d+K?)`), "Nexus", "Nexus ${1}"},
+}
+
+// ciscoSeries derives the Cisco family and series from the model, e.g. "Catalyst" and
+// "Catalyst 2960" for "WS-C2960X-48TS-L", and "ISR" and "ISR 2900" for "C2900".
+func ciscoSeries(model, sysDescr string) (string, string) {
+	upper := strings.ToUpper(model)
+	for _, entry := range ciscoFamilies {
+		match := entry.model.FindStringSubmatchIndex(upper)
+		if match == nil {
+			continue
+		}
+		series := strings.TrimSpace(string(entry.model.ExpandString(nil, entry.series, upper, match)))
+		return series, entry.family
+	}
+	return "", ""
+}
+
+// nokiaModel splits a Nokia model into its number and family, e.g. "7750" and "SR".
+var nokiaModel = regexp.MustCompile(`^(\d+)\s+([A-Z]+)`)
+
+// nokiaSeries takes the family from the model name, e.g. "SR" and "7750 SR" for "7750 SR-12".
+func nokiaSeries(model, sysDescr string) (string, string) {
+	match := nokiaModel.FindStringSubmatch(model)
+	if match == nil {
+		return "", ""
+	}
+	return match[1] + " " + match[2], match[2]
+}
+
+// linuxFamily takes the distribution from the kernel build, e.g. "Ubuntu" for "#101-Ubuntu SMP".
+func linuxFamily(model, sysDescr string) (string, string) {
+	for _, distribution := range []string{"Ubuntu", "Debian", "el7", "el8", "el9", "Red Hat", "CentOS"} {
+		if strings.Contains(sysDescr, distribution) {
+			if strings.HasPrefix(distribution, "el") {
+				return "", "RHEL"
+			}
+			return "", distribution
+		}
+	}
+	return "", ""
+}
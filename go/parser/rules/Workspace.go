@@ -0,0 +1,78 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import "github.com/saichler/l8pollaris/go/types/l8tpollaris"
+
+// Workspaces are scoped so that chained rules only see what their own attribute produced:
+//
+//   - Job scope holds the values shared by every attribute of a job: Input, JobEnded and TargetId.
+//     It is created once per job and is never written to by rules.
+//   - Attribute scope is a fresh copy of the job scope per attribute. It holds the PropertyId,
+//     the Output chained between the attribute's rules, Instances and the accumulated
+//     parameters of the attribute's rules.
+//   - Rule scope is the rule's own params map, passed to ParsingRule.Parse. Before a rule runs
+//     its params are applied to the attribute scope, so a later rule of the same attribute
+//     (e.g. CTableToInstances after StringToCTable) can still read a key_column set earlier.
+//
+// Nothing written in one attribute's scope is visible to the next attribute.
+
+// jobScopeKeys lists the workspace keys that belong to the job scope.
+var jobScopeKeys = []string{Input, JobEnded, TargetId}
+
+// NewJobWorkspace creates the job-level workspace for the decoded job input.
+// hostId is stored under the TargetId key (see Parser.go for the historical naming).
+func NewJobWorkspace(input interface{}, jobEnded int64, hostId string) map[string]interface{} {
+	workSpace := make(map[string]interface{})
+	workSpace[Input] = input
+	workSpace[JobEnded] = jobEnded
+	workSpace[TargetId] = hostId
+	return workSpace
+}
+
+// NewAttributeWorkspace creates an attribute-level workspace inheriting only the
+// job-scope values of jobWorkSpace, and sets the attribute's PropertyId.
+func NewAttributeWorkspace(jobWorkSpace map[string]interface{}, propertyId string) map[string]interface{} {
+	workSpace := make(map[string]interface{}, len(jobScopeKeys)+4)
+	for _, key := range jobScopeKeys {
+		if v, ok := jobWorkSpace[key]; ok {
+			workSpace[key] = v
+		}
+	}
+	workSpace[PropertyId] = propertyId
+	return workSpace
+}
+
+// ApplyRuleParams copies a rule's parameter values into the attribute workspace.
+// Job-scope keys are protected so a parameter can never shadow the job input.
+func ApplyRuleParams(workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter) {
+	for name, param := range params {
+		if isJobScopeKey(name) || param == nil {
+			continue
+		}
+		workSpace[name] = param.Value
+	}
+}
+
+// isJobScopeKey returns true if the key belongs to the job scope.
+func isJobScopeKey(key string) bool {
+	for _, k := range jobScopeKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
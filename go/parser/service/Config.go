@@ -0,0 +1,114 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import "time"
+
+// DefaultUnchangedRefresh is the default Config.UnchangedRefresh.
+const DefaultUnchangedRefresh = 10 * time.Minute
+
+// DefaultFullRefresh is the default Config.FullRefresh.
+const DefaultFullRefresh = 10 * time.Minute
+
+// DefaultGhostMaxAge is the default Config.GhostMaxAge.
+const DefaultGhostMaxAge = 24 * time.Hour
+
+// DefaultDeleteGrace is the default Config.DeleteGrace.
+const DefaultDeleteGrace = 5 * time.Minute
+
+// Config holds the optional settings of a ParsingService. It is passed as the second
+// service argument (sla.Args()[1]) by ActivateWithConfig; services activated with
+// Activate use the defaults.
+type Config struct {
+	// PersistJobs enables persisting every received job for replay.
+	PersistJobs bool
+	// JobStore persists the jobs when PersistJobs is set.
+	// Defaults to a FileJobStore with DefaultJobStoreConfig.
+	JobStore JobStore
+	// Redactor masks sensitive values of the jobs before they are persisted.
+	// NewConfig sets it to the DefaultRedactionRules; nil persists the jobs as received.
+	Redactor *Redactor
+	// ErrorPolicy is how the parser reacts to a failing attribute. The zero value is
+	// FailFast; ContinueOnError still PATCHes the attributes that parsed.
+	ErrorPolicy ErrorPolicy
+	// Workers is the number of jobs parsed concurrently. Zero uses the number of CPUs.
+	Workers int
+	// QueueDepth is the number of jobs queued per worker. Zero uses DefaultQueueDepth.
+	QueueDepth int
+	// SubmitTimeout bounds how long Post blocks on a full queue before dropping the job.
+	// Zero blocks until the job is queued.
+	SubmitTimeout time.Duration
+	// UnchangedRefresh is how long a job whose result did not change is skipped before it
+	// is applied again anyway. Zero never re-applies unchanged results.
+	UnchangedRefresh time.Duration
+	// DeltaPatches sends only the fields that changed since the last object sent for
+	// the same primary key, pollaris and job, instead of the whole object. Off by default,
+	// enable it with ActivateWithConfig.
+	DeltaPatches bool
+	// FullRefresh is how often the whole object is sent anyway when DeltaPatches is set.
+	// Zero only sends it when a delta cannot express the change.
+	FullRefresh time.Duration
+	// ReconcileTables treats table polls as authoritative for the collections they
+	// populate: components missing from the current poll are sent marked as not present.
+	ReconcileTables bool
+	// GhostMaxAge is how long a component marked as not present keeps being sent.
+	// Zero sends it until it shows up again.
+	GhostMaxAge time.Duration
+	// NotPresentIntFields are the full protobuf names of the integer status fields that
+	// hold IF-MIB ifOperStatus values, e.g. "types.Port.oper_status", marked as not present
+	// with notPresent(6). Other integer status fields of a removed component are left as is.
+	NotPresentIntFields []string
+	// DeleteMissingInstances deletes from the inventory the instances that disappeared
+	// from a CTableToInstances poll, once missing for DeleteGrace. Off by default,
+	// enable it with ActivateWithConfig.
+	DeleteMissingInstances bool
+	// DeleteGrace is how long an instance must be missing before it is deleted.
+	DeleteGrace time.Duration
+	// KeySources maps the primary key fields of the parsed element to the job attributes
+	// they are set from. Empty sets the first primary key from the job's HostId.
+	KeySources []*KeySource
+	// DeadLetters is the number of failed jobs kept for Reparse. Zero uses DefaultDeadLetters.
+	DeadLetters int
+	// Sender sends the PATCH and DELETE elements to the inventory. Nil sends them with
+	// the vnic to the leader of the inventory cache service.
+	Sender Sender
+	// ShutdownTimeout bounds how long DeActivate waits for the queued jobs to be parsed and
+	// the pending elements to be sent. Zero waits without a limit.
+	ShutdownTimeout time.Duration
+	// DeviceClassifierRules is a JSON file of rules.ClassifierRule added to the built-in
+	// device type classifier table, replacing the built-in rules with the same name.
+	DeviceClassifierRules string
+}
+
+// NewConfig returns a Config with the default settings.
+func NewConfig() *Config {
+	redactor, _ := NewRedactor(DefaultRedactionRules()...)
+	return &Config{Redactor: redactor, UnchangedRefresh: DefaultUnchangedRefresh,
+		FullRefresh:     DefaultFullRefresh,
+		GhostMaxAge:     DefaultGhostMaxAge,
+		DeleteGrace:     DefaultDeleteGrace,
+		ShutdownTimeout: DefaultShutdownTimeout}
+}
+
+// configFromArgs returns the Config passed in the service arguments, or the defaults.
+func configFromArgs(args []interface{}) *Config {
+	if len(args) > 1 {
+		if config, ok := args[1].(*Config); ok && config != nil {
+			return config
+		}
+	}
+	return NewConfig()
+}
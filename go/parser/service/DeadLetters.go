@@ -0,0 +1,146 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// DefaultDeadLetters is the default Config.DeadLetters.
+const DefaultDeadLetters = 100
+
+// DeadLetter is a job that failed to parse, kept so it can be parsed again once the
+// pollaris or rule causing the failure is fixed. It is dropped once a job of the same
+// key parses.
+type DeadLetter struct {
+	// Key identifies the dead letter, see DeadLetterKey.
+	Key   string
+	Job   *l8tpollaris.CJob
+	Error string
+	// Failed is the time of the last failure.
+	Failed time.Time
+	// Attempts is the number of times the same job failed.
+	Attempts int
+}
+
+// DeadLetterKey returns the key of the dead letter of the job's pollaris, job, host
+// and target. A later failure of the same key replaces the earlier dead letter.
+func DeadLetterKey(job *l8tpollaris.CJob) string {
+	return jobKey(job.PollarisName, job.JobName, job.HostId) + "." + job.TargetId
+}
+
+// DeadLetterStore holds the most recent failed jobs, up to its capacity, dropping the
+// oldest dead letter when full. It is safe for concurrent use.
+type DeadLetterStore struct {
+	mtx      *sync.Mutex
+	capacity int
+	letters  map[string]*DeadLetter
+	// order holds the keys from the oldest to the most recent failure.
+	order   []string
+	dropped int64
+}
+
+// NewDeadLetterStore creates a DeadLetterStore holding up to capacity failed jobs.
+// A capacity of zero or less uses DefaultDeadLetters.
+func NewDeadLetterStore(capacity int) *DeadLetterStore {
+	if capacity <= 0 {
+		capacity = DefaultDeadLetters
+	}
+	return &DeadLetterStore{mtx: &sync.Mutex{}, capacity: capacity, letters: make(map[string]*DeadLetter)}
+}
+
+// Add records the failed job with its error. A failure of the same job again
+// increments the Attempts of its dead letter.
+func (this *DeadLetterStore) Add(job *l8tpollaris.CJob, err error) {
+	key := DeadLetterKey(job)
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	attempts := 1
+	if letter, ok := this.letters[key]; ok {
+		if letter.Job.Ended == job.Ended {
+			attempts = letter.Attempts + 1
+		}
+		this.remove(key)
+	}
+	for len(this.order) >= this.capacity {
+		this.remove(this.order[0])
+		this.dropped++
+	}
+	this.letters[key] = &DeadLetter{Key: key, Job: job, Error: err.Error(), Failed: time.Now(), Attempts: attempts}
+	this.order = append(this.order, key)
+}
+
+// List returns the dead letters from the oldest to the most recent failure.
+func (this *DeadLetterStore) List() []*DeadLetter {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	result := make([]*DeadLetter, 0, len(this.order))
+	for _, key := range this.order {
+		letter := *this.letters[key]
+		result = append(result, &letter)
+	}
+	return result
+}
+
+// Get returns the dead letter of key, or nil if there is none.
+func (this *DeadLetterStore) Get(key string) *DeadLetter {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	letter, ok := this.letters[key]
+	if !ok {
+		return nil
+	}
+	result := *letter
+	return &result
+}
+
+// Remove drops the dead letter of the job's key, e.g. once a job of the key parsed.
+func (this *DeadLetterStore) Remove(job *l8tpollaris.CJob) {
+	key := DeadLetterKey(job)
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	if _, ok := this.letters[key]; ok {
+		this.remove(key)
+	}
+}
+
+// Len returns the number of dead letters.
+func (this *DeadLetterStore) Len() int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return len(this.order)
+}
+
+// Dropped returns the number of dead letters dropped because the store was full.
+func (this *DeadLetterStore) Dropped() int64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.dropped
+}
+
+func (this *DeadLetterStore) remove(key string) {
+	delete(this.letters, key)
+	for i, k := range this.order {
+		if k == key {
+			this.order = append(this.order[:i], this.order[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// defaultKeyColumns are the delete CMap keys forming the instance key when they cannot
+// be derived from the originating poll, e.g. for kubectl polls without column names.
+var defaultKeyColumns = []string{"namespace", "name"}
+
+// KeyColumnNames returns the names of the key columns of a table poll: the key_column
+// indices of its StringToCTable rule resolved against the poll's column names. Each key
+// column has the candidate names it may be found under in a delete CMap, e.g. the header
+// "NAMESPACE" and the last segment of the field "metadata.namespace".
+func KeyColumnNames(poll *l8tpollaris.L8Poll) [][]string {
+	keyColumns := pollKeyColumns(poll)
+	if len(keyColumns) == 0 {
+		return nil
+	}
+	spec := struct {
+		Fields      []string `json:"fields"`
+		ColumnNames []string `json:"columnNames"`
+	}{}
+	if json.Unmarshal([]byte(poll.What), &spec) != nil {
+		return nil
+	}
+
+	result := make([][]string, 0, len(keyColumns))
+	for _, col := range keyColumns {
+		names := make([]string, 0, 2)
+		if col >= 0 && col < len(spec.ColumnNames) {
+			names = append(names, spec.ColumnNames[col])
+		}
+		if col >= 0 && col < len(spec.Fields) {
+			field := spec.Fields[col]
+			names = append(names, field[strings.LastIndex(field, ".")+1:])
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		result = append(result, names)
+	}
+	return result
+}
+
+// pollKeyColumns returns the key_column indices of the poll's StringToCTable rule.
+func pollKeyColumns(poll *l8tpollaris.L8Poll) []int {
+	schema := (&rules.StringToCTable{}).ParamSchema()
+	for _, attr := range poll.Attributes {
+		for _, rule := range attr.Rules {
+			if rule.Name != "StringToCTable" {
+				continue
+			}
+			typed, err := rules.ParseParams(schema, rule.Params)
+			if err != nil {
+				return nil
+			}
+			return typed.IntList(rules.KeyColumn)
+		}
+	}
+	return nil
+}
+
+// DeleteKey joins the values of the key columns found in the delete CMap with "/",
+// the way CTableToInstances builds the Key of an instance, skipping empty values such
+// as the namespace of cluster scoped resources.
+func DeleteKey(cmap *l8tpollaris.CMap, keyColumns [][]string) string {
+	if len(keyColumns) == 0 {
+		for _, name := range defaultKeyColumns {
+			keyColumns = append(keyColumns, []string{name})
+		}
+	}
+	values := make([]string, 0, len(keyColumns))
+	for _, names := range keyColumns {
+		if value := lookupCMap(cmap, names...); value != "" {
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, "/")
+}
+
+// lookupCMap returns the first value of the CMap found under any of the names, ignoring
+// case and underscores.
+func lookupCMap(cmap *l8tpollaris.CMap, names ...string) string {
+	for _, name := range names {
+		target := normalizeKeyName(name)
+		for key, value := range cmap.Data {
+			if normalizeKeyName(key) == target {
+				return string(value)
+			}
+		}
+	}
+	return ""
+}
+
+func normalizeKeyName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// SetDeleteKeyFields sets the primary key fields of a delete stub. The fields of the key
+// sources are already set from the job, except the KeyFromOutput ones as a delete job is
+// not parsed. Each other field is set from the delete CMap entry
+// of the same name if any. The composite key identifies the instance as a whole, so it is
+// only set when a single string field is left: with several, which field it belongs to is
+// ambiguous and they are all reported. It returns the names of the fields that could not
+// be set.
+func SetDeleteKeyFields(elem interface{}, primaryKeys []string, keySources []*KeySource,
+	cmap *l8tpollaris.CMap, key string) []string {
+	preset := make(map[string]bool, len(keySources))
+	for _, source := range keySources {
+		if source.Kind != KeyFromOutput {
+			preset[source.Field] = true
+		}
+	}
+	v := reflect.ValueOf(elem).Elem()
+	unset := make([]string, 0)
+	missing := make([]string, 0)
+	for _, name := range primaryKeys {
+		if preset[name] {
+			continue
+		}
+		field := v.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			unset = append(unset, name)
+			continue
+		}
+		if value := lookupCMap(cmap, name); value != "" && setKeyField(field, value) {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if key != "" && len(missing) == 1 {
+		field := v.FieldByName(missing[0])
+		if field.Kind() == reflect.String {
+			field.SetString(key)
+			return unset
+		}
+	}
+	return append(unset, missing...)
+}
+
+// setKeyField sets a string or numeric field from its string value.
+func setKeyField(field reflect.Value, value string) bool {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			field.SetInt(n)
+			return true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err == nil {
+			field.SetUint(n)
+			return true
+		}
+	}
+	return false
+}
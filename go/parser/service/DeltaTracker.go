@@ -0,0 +1,187 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DeltaTracker keeps the last object sent to the inventory per key and computes the
+// field level delta of a newly parsed object against it, so only the changed fields
+// travel over the bus. It is safe for concurrent use.
+//
+// A merge PATCH cannot clear a field, so when a field or map entry that was sent before
+// is missing from the new object, the whole object is sent instead of a delta.
+type DeltaTracker struct {
+	mtx *sync.Mutex
+	// fullRefresh sends the whole object once the last full send is older than it.
+	fullRefresh time.Duration
+	last        map[string]*sentObject
+}
+
+type sentObject struct {
+	obj      proto.Message
+	fullSent time.Time
+}
+
+// NewDeltaTracker creates a DeltaTracker sending the whole object at least once every
+// fullRefresh; zero only sends it the first time and when a delta cannot express a change.
+func NewDeltaTracker(fullRefresh time.Duration) *DeltaTracker {
+	return &DeltaTracker{mtx: &sync.Mutex{}, fullRefresh: fullRefresh, last: make(map[string]*sentObject)}
+}
+
+// Delta returns the object to PATCH for the parsed object of key: the object itself the
+// first time, on a full refresh or when a delta cannot express the change, otherwise a
+// new object holding the changed fields and the primaryKeys fields. It returns false when
+// nothing changed. Objects that are not protobuf messages are always sent whole.
+func (this *DeltaTracker) Delta(key string, obj interface{}, primaryKeys []string) (interface{}, bool) {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return obj, true
+	}
+	now := time.Now()
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	last, ok := this.last[key]
+	if !ok || (this.fullRefresh > 0 && now.Sub(last.fullSent) >= this.fullRefresh) {
+		this.last[key] = &sentObject{obj: msg, fullSent: now}
+		return obj, true
+	}
+
+	delta := msg.ProtoReflect().New()
+	if !diffMessage(last.obj.ProtoReflect(), msg.ProtoReflect(), delta) {
+		this.last[key] = &sentObject{obj: msg, fullSent: now}
+		return obj, true
+	}
+	last.obj = msg
+	if isEmptyMessage(delta) {
+		return nil, false
+	}
+	result := delta.Interface()
+	copyFields(obj, result, primaryKeys)
+	return result, true
+}
+
+// Forget drops the last object of key, so the next object of key is sent whole.
+func (this *DeltaTracker) Forget(key string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.last, key)
+}
+
+// diffMessage sets on delta the fields of curr that differ from prev, descending into
+// nested messages and maps. It returns false when a field set in prev is not set in curr.
+func diffMessage(prev, curr, delta protoreflect.Message) bool {
+	ok := true
+	prev.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		ok = curr.Has(fd)
+		return ok
+	})
+	if !ok {
+		return false
+	}
+
+	curr.Range(func(fd protoreflect.FieldDescriptor, cv protoreflect.Value) bool {
+		if !prev.Has(fd) {
+			delta.Set(fd, cv)
+			return true
+		}
+		pv := prev.Get(fd)
+		switch {
+		case fd.IsMap():
+			ok = diffMap(fd, pv.Map(), cv.Map(), delta)
+		case fd.IsList():
+			if !pv.Equal(cv) {
+				delta.Set(fd, cv)
+			}
+		case fd.Message() != nil:
+			sub := delta.Mutable(fd).Message()
+			ok = diffMessage(pv.Message(), cv.Message(), sub)
+			if ok && isEmptyMessage(sub) {
+				delta.Clear(fd)
+			}
+		default:
+			if !pv.Equal(cv) {
+				delta.Set(fd, cv)
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+// diffMap sets on delta the entries of curr that differ from prev. It returns false
+// when an entry of prev is missing from curr.
+func diffMap(fd protoreflect.FieldDescriptor, prev, curr protoreflect.Map, delta protoreflect.Message) bool {
+	ok := true
+	prev.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		ok = curr.Has(k)
+		return ok
+	})
+	if !ok {
+		return false
+	}
+
+	deltaMap := delta.Mutable(fd).Map()
+	isMessage := fd.MapValue().Message() != nil
+	curr.Range(func(k protoreflect.MapKey, cv protoreflect.Value) bool {
+		pv := prev.Get(k)
+		switch {
+		case !prev.Has(k):
+			deltaMap.Set(k, cv)
+		case isMessage:
+			sub := deltaMap.NewValue()
+			ok = diffMessage(pv.Message(), cv.Message(), sub.Message())
+			if ok && !isEmptyMessage(sub.Message()) {
+				deltaMap.Set(k, sub)
+			}
+		case !pv.Equal(cv):
+			deltaMap.Set(k, cv)
+		}
+		return ok
+	})
+	if deltaMap.Len() == 0 {
+		delta.Clear(fd)
+	}
+	return ok
+}
+
+func isEmptyMessage(msg protoreflect.Message) bool {
+	empty := true
+	msg.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		empty = false
+		return false
+	})
+	return empty
+}
+
+// copyFields copies the named fields from one struct pointer to another of the same type.
+func copyFields(from, to interface{}, fields []string) {
+	src := reflect.ValueOf(from).Elem()
+	dst := reflect.ValueOf(to).Elem()
+	for _, name := range fields {
+		field := dst.FieldByName(name)
+		if field.IsValid() && field.CanSet() {
+			field.Set(src.FieldByName(name))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// InstanceTracker remembers the instances produced by the last full table of each
+// table key, e.g. the pods of a cluster, and reports the instances that disappeared,
+// so they can be deleted from the inventory. An instance is only reported once it has
+// been missing for the grace period, so an instance flapping in and out of the table
+// is not deleted and re-created. It is safe for concurrent use.
+type InstanceTracker struct {
+	mtx    *sync.Mutex
+	grace  time.Duration
+	tables map[string]*instanceTable
+}
+
+type instanceTable struct {
+	// stubs holds an object with only the primary key fields set per known instance.
+	stubs map[string]interface{}
+	// missing holds the time each known instance was first missing from the table.
+	missing map[string]time.Time
+}
+
+// NewInstanceTracker creates an InstanceTracker reporting instances missing for grace.
+func NewInstanceTracker(grace time.Duration) *InstanceTracker {
+	return &InstanceTracker{mtx: &sync.Mutex{}, grace: grace, tables: make(map[string]*instanceTable)}
+}
+
+// Update records the instances of the latest full table of tableKey, identified by their
+// primaryKeys fields, and returns the instances that have been missing for the grace
+// period, as objects with only the primary key fields set. Returned instances are
+// forgotten.
+func (this *InstanceTracker) Update(tableKey string, instances []interface{}, primaryKeys []string) []interface{} {
+	now := time.Now()
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	table, ok := this.tables[tableKey]
+	if !ok {
+		table = &instanceTable{stubs: make(map[string]interface{}), missing: make(map[string]time.Time)}
+		this.tables[tableKey] = table
+	}
+
+	current := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		key := primaryKeyOf(inst, primaryKeys)
+		current[key] = true
+		delete(table.missing, key)
+		if _, ok := table.stubs[key]; !ok {
+			table.stubs[key] = newStub(inst, primaryKeys)
+		}
+	}
+
+	gone := make([]interface{}, 0)
+	for key, stub := range table.stubs {
+		if current[key] {
+			continue
+		}
+		since, ok := table.missing[key]
+		if !ok {
+			since = now
+			table.missing[key] = since
+		}
+		if now.Sub(since) >= this.grace {
+			gone = append(gone, stub)
+			delete(table.stubs, key)
+			delete(table.missing, key)
+		}
+	}
+	return gone
+}
+
+// Forget drops an instance of tableKey, e.g. after it was deleted explicitly.
+func (this *InstanceTracker) Forget(tableKey string, inst interface{}, primaryKeys []string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	table, ok := this.tables[tableKey]
+	if !ok {
+		return
+	}
+	key := primaryKeyOf(inst, primaryKeys)
+	delete(table.stubs, key)
+	delete(table.missing, key)
+}
+
+// Known returns the number of instances currently known for tableKey.
+func (this *InstanceTracker) Known(tableKey string) int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	table, ok := this.tables[tableKey]
+	if !ok {
+		return 0
+	}
+	return len(table.stubs)
+}
+
+// newStub returns a new object of the instance's type with only the primaryKeys fields set.
+func newStub(inst interface{}, primaryKeys []string) interface{} {
+	stub := reflect.New(reflect.ValueOf(inst).Elem().Type()).Interface()
+	copyFields(inst, stub, primaryKeys)
+	return stub
+}
+
+// primaryKeyOf returns the "/" separated values of the primaryKeys fields of obj.
+func primaryKeyOf(obj interface{}, primaryKeys []string) string {
+	buff := bytes.Buffer{}
+	v := reflect.ValueOf(obj).Elem()
+	for i, name := range primaryKeys {
+		if i > 0 {
+			buff.WriteString("/")
+		}
+		if field := v.FieldByName(name); field.IsValid() {
+			buff.WriteString(fmt.Sprint(field.Interface()))
+		}
+	}
+	return buff.String()
+}
@@ -0,0 +1,345 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// jobDirMode is the file mode of the job store directories.
+	jobDirMode = 0750
+	// jobFileMode is the file mode of the persisted jobs and index files.
+	jobFileMode = 0640
+	// jobIndexFile is the name of the per-target index file.
+	jobIndexFile = "index.json"
+	// gzipSuffix is the file suffix of compressed jobs.
+	gzipSuffix = ".gz"
+)
+
+// JobStore persists collection jobs so they can be replayed later.
+type JobStore interface {
+	// Save persists a job.
+	Save(job *l8tpollaris.CJob) error
+	// Load returns the most recent persisted job for the given key.
+	Load(pollarisName, jobName, targetId, hostId string) (*l8tpollaris.CJob, error)
+	// History returns the persisted records of the given key, oldest first.
+	History(pollarisName, jobName, targetId, hostId string) ([]*JobRecord, error)
+	// LoadRecord returns the job of a record returned by History.
+	LoadRecord(record *JobRecord) (*l8tpollaris.CJob, error)
+}
+
+// JobRecord is an entry of a target's job index.
+type JobRecord struct {
+	File         string `json:"file"`
+	PollarisName string `json:"pollarisName"`
+	JobName      string `json:"jobName"`
+	TargetId     string `json:"targetId"`
+	HostId       string `json:"hostId"`
+	// Ended is the job's end timestamp, as reported by the collector.
+	Ended int64 `json:"ended"`
+	// Saved is the time the job was persisted, in unix nanoseconds.
+	Saved      int64 `json:"saved"`
+	Size       int   `json:"size"`
+	Compressed bool  `json:"compressed"`
+}
+
+func (this *JobRecord) key() string {
+	return jobKey(this.PollarisName, this.JobName, this.HostId)
+}
+
+// JobStoreConfig configures a FileJobStore.
+type JobStoreConfig struct {
+	// Dir is the root directory; jobs are stored in one sub directory per target.
+	Dir string
+	// MaxPerKey is the number of jobs kept per pollaris/job/target/host key. Zero keeps all.
+	MaxPerKey int
+	// MaxAge removes jobs older than this duration. Zero keeps jobs regardless of age.
+	// The most recent job of a key is always kept.
+	MaxAge time.Duration
+	// Compress gzips the persisted jobs.
+	Compress bool
+}
+
+// DefaultJobStoreConfig returns the configuration used when job persistence is enabled
+// without an explicit configuration.
+func DefaultJobStoreConfig() *JobStoreConfig {
+	return &JobStoreConfig{Dir: JobFileLocation, MaxPerKey: 5, Compress: true}
+}
+
+// FileJobStore is a JobStore keeping jobs as protojson files, optionally gzipped, in one
+// directory per target, with an index.json per target listing the kept jobs.
+type FileJobStore struct {
+	config *JobStoreConfig
+	mtx    *sync.Mutex
+	// indexes caches the loaded per-target indexes, keyed by target directory.
+	indexes map[string][]*JobRecord
+}
+
+// NewFileJobStore creates a FileJobStore, using DefaultJobStoreConfig when config is nil.
+func NewFileJobStore(config *JobStoreConfig) *FileJobStore {
+	if config == nil {
+		config = DefaultJobStoreConfig()
+	}
+	return &FileJobStore{config: config, mtx: &sync.Mutex{}, indexes: make(map[string][]*JobRecord)}
+}
+
+// Save persists the job and applies the retention policy to the target's jobs.
+func (this *FileJobStore) Save(job *l8tpollaris.CJob) error {
+	data, err := protojson.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := &JobRecord{PollarisName: job.PollarisName, JobName: job.JobName, TargetId: job.TargetId,
+		HostId: job.HostId, Ended: job.Ended, Saved: now.UnixNano(), Compressed: this.config.Compress}
+	record.File = jobRecordFileName(record)
+	if record.Compressed {
+		data, err = compress(data)
+		if err != nil {
+			return err
+		}
+	}
+	record.Size = len(data)
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	dir := this.targetDir(job.TargetId)
+	err = os.MkdirAll(dir, jobDirMode)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, record.File), data, jobFileMode)
+	if err != nil {
+		return err
+	}
+
+	index, err := this.index(dir)
+	if err != nil {
+		return err
+	}
+	index = append(index, record)
+	index = this.retain(dir, index, now)
+	this.indexes[dir] = index
+	return writeIndex(dir, index)
+}
+
+// Load returns the most recent persisted job for the given key.
+func (this *FileJobStore) Load(pollarisName, jobName, targetId, hostId string) (*l8tpollaris.CJob, error) {
+	records, err := this.History(pollarisName, jobName, targetId, hostId)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return this.LoadRecord(records[len(records)-1])
+}
+
+// History returns the persisted records of the given key, oldest first.
+func (this *FileJobStore) History(pollarisName, jobName, targetId, hostId string) ([]*JobRecord, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	index, err := this.index(this.targetDir(targetId))
+	if err != nil {
+		return nil, err
+	}
+	key := jobKey(pollarisName, jobName, hostId)
+	result := make([]*JobRecord, 0)
+	for _, record := range index {
+		if record.key() == key {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// LoadRecord returns the job of a record returned by History.
+func (this *FileJobStore) LoadRecord(record *JobRecord) (*l8tpollaris.CJob, error) {
+	return ReadJobFile(filepath.Join(this.targetDir(record.TargetId), record.File))
+}
+
+// retain applies MaxPerKey and MaxAge to the records of every key of the index, deleting
+// the files of the dropped records, and returns the remaining index, keeping its order.
+// Applying it to every key ages out the jobs of keys that are no longer collected.
+func (this *FileJobStore) retain(dir string, index []*JobRecord, now time.Time) []*JobRecord {
+	byKey := make(map[string][]*JobRecord)
+	for _, record := range index {
+		byKey[record.key()] = append(byKey[record.key()], record)
+	}
+
+	drop := make(map[*JobRecord]bool)
+	for _, keyRecords := range byKey {
+		sort.Slice(keyRecords, func(i, j int) bool {
+			return keyRecords[i].Saved < keyRecords[j].Saved
+		})
+		if this.config.MaxPerKey > 0 && len(keyRecords) > this.config.MaxPerKey {
+			for _, record := range keyRecords[:len(keyRecords)-this.config.MaxPerKey] {
+				drop[record] = true
+			}
+		}
+		if this.config.MaxAge > 0 {
+			oldest := now.Add(-this.config.MaxAge).UnixNano()
+			for _, record := range keyRecords[:len(keyRecords)-1] {
+				if record.Saved < oldest {
+					drop[record] = true
+				}
+			}
+		}
+	}
+	if len(drop) == 0 {
+		return index
+	}
+
+	result := make([]*JobRecord, 0, len(index)-len(drop))
+	for _, record := range index {
+		if drop[record] {
+			os.Remove(filepath.Join(dir, record.File))
+			continue
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// index returns the cached index of a target directory, loading it from disk on first use.
+func (this *FileJobStore) index(dir string) ([]*JobRecord, error) {
+	if index, ok := this.indexes[dir]; ok {
+		return index, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, jobIndexFile))
+	if os.IsNotExist(err) {
+		this.indexes[dir] = []*JobRecord{}
+		return this.indexes[dir], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := make([]*JobRecord, 0)
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return nil, errors.New("corrupted job index " + filepath.Join(dir, jobIndexFile) + ": " + err.Error())
+	}
+	this.indexes[dir] = index
+	return index, nil
+}
+
+func (this *FileJobStore) targetDir(targetId string) string {
+	return filepath.Join(this.config.Dir, sanitizeFileName(targetId))
+}
+
+func writeIndex(dir string, index []*JobRecord) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves a truncated index behind
+	tmp := filepath.Join(dir, jobIndexFile+".tmp")
+	err = os.WriteFile(tmp, data, jobFileMode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, jobIndexFile))
+}
+
+// ReadJobFile reads a persisted protojson job, transparently decompressing gzipped files.
+func ReadJobFile(path string) (*l8tpollaris.CJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, gzipSuffix) {
+		data, err = decompress(data)
+		if err != nil {
+			return nil, errors.New("failed to decompress job " + path + ": " + err.Error())
+		}
+	}
+	job := &l8tpollaris.CJob{}
+	err = protojson.Unmarshal(data, job)
+	if err != nil {
+		return nil, errors.New("failed to unmarshal job " + path + ": " + err.Error())
+	}
+	return job, nil
+}
+
+func jobKey(pollarisName, jobName, hostId string) string {
+	return pollarisName + "." + jobName + "." + hostId
+}
+
+func jobRecordFileName(record *JobRecord) string {
+	name := sanitizeFileName(jobKey(record.PollarisName, record.JobName, record.HostId)) + "." +
+		strconv.FormatInt(record.Saved, 10) + ".json"
+	if record.Compressed {
+		name += gzipSuffix
+	}
+	return name
+}
+
+// sanitizeFileName replaces the characters that are not safe in a file name and escapes
+// "..", so a target or host id can never resolve outside of the store directory.
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.ReplaceAll(name, "..", "__")
+	if name == "" || name == "." {
+		return "_"
+	}
+	return name
+}
+
+func compress(data []byte) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	writer := gzip.NewWriter(buff)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,141 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// JobDecision is the outcome of checking a job against the last applied job of its key.
+type JobDecision int
+
+const (
+	// JobApply means the job is new or changed and must be parsed and applied.
+	JobApply JobDecision = iota
+	// JobStale means a job that ended later was already applied.
+	JobStale
+	// JobUnchanged means the job's result is identical to the last applied one.
+	JobUnchanged
+)
+
+func (this JobDecision) String() string {
+	switch this {
+	case JobStale:
+		return "stale"
+	case JobUnchanged:
+		return "unchanged"
+	}
+	return "apply"
+}
+
+// JobTracker remembers the Ended time and result hash of the last applied job per
+// (HostId, pollaris, job), so that jobs arriving out of order are dropped instead of
+// overwriting fresher inventory data, and identical results are not parsed and PATCHed
+// again every cadence. It is safe for concurrent use.
+type JobTracker struct {
+	mtx *sync.Mutex
+	// refresh re-applies an unchanged result once the last application is older than it.
+	refresh   time.Duration
+	last      map[string]*appliedJob
+	stale     int64
+	unchanged int64
+}
+
+type appliedJob struct {
+	ended   int64
+	hash    [sha256.Size]byte
+	applied time.Time
+	// previous is the last applied job this one replaced, restored by Forget.
+	previous *appliedJob
+}
+
+// NewJobTracker creates a JobTracker. Unchanged results are applied again once the last
+// application is older than refresh, so an inventory that lost the data heals on its
+// own; zero never re-applies an unchanged result.
+func NewJobTracker(refresh time.Duration) *JobTracker {
+	return &JobTracker{mtx: &sync.Mutex{}, refresh: refresh, last: make(map[string]*appliedJob)}
+}
+
+// Check decides whether the job must be applied. A job to apply is recorded as the last
+// applied job of its key; call Forget if it ends up not being applied.
+// Jobs without an Ended time are never considered stale.
+func (this *JobTracker) Check(job *l8tpollaris.CJob) JobDecision {
+	key := jobKey(job.PollarisName, job.JobName, job.HostId)
+	hash := sha256.Sum256(job.Result)
+	now := time.Now()
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	last, ok := this.last[key]
+	if ok {
+		if job.Ended != 0 && job.Ended < last.ended {
+			this.stale++
+			return JobStale
+		}
+		if hash == last.hash && (this.refresh <= 0 || now.Sub(last.applied) < this.refresh) {
+			this.unchanged++
+			if job.Ended > last.ended {
+				last.ended = job.Ended
+			}
+			return JobUnchanged
+		}
+	}
+	var previous *appliedJob
+	if ok {
+		restored := *last
+		restored.previous = nil
+		previous = &restored
+	}
+	this.last[key] = &appliedJob{ended: job.Ended, hash: hash, applied: now, previous: previous}
+	return JobApply
+}
+
+// Forget undoes the Check of a job that ended up not being applied, e.g. after its parse
+// failed: the last applied job before it is restored, so the next job with the failed
+// job's result is applied again, while a job older than the restored one is still stale.
+func (this *JobTracker) Forget(job *l8tpollaris.CJob) {
+	key := jobKey(job.PollarisName, job.JobName, job.HostId)
+	hash := sha256.Sum256(job.Result)
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	last, ok := this.last[key]
+	if !ok || last.ended != job.Ended || last.hash != hash {
+		return
+	}
+	if last.previous == nil {
+		delete(this.last, key)
+		return
+	}
+	this.last[key] = last.previous
+}
+
+// Stale returns the number of jobs dropped because a later job was already applied.
+func (this *JobTracker) Stale() int64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.stale
+}
+
+// Unchanged returns the number of jobs skipped because their result did not change.
+func (this *JobTracker) Unchanged() int64 {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return this.unchanged
+}
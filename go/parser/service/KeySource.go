@@ -0,0 +1,166 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// KeySourceKind is the job attribute a primary key field is taken from.
+type KeySourceKind int
+
+const (
+	// KeyFromHostId takes the key from the job's HostId.
+	KeyFromHostId KeySourceKind = iota
+	// KeyFromTargetId takes the key from the job's TargetId.
+	KeyFromTargetId
+	// KeyFromLinksId takes the key from the job's LinksId.
+	KeyFromLinksId
+	// KeyFromArgument takes the key from the job argument Name, e.g. "namespace".
+	KeyFromArgument
+	// KeyFromOutput takes the key from the value produced by the rules of the poll
+	// attribute whose PropertyId is Name, e.g. "k8spod.name", once the job is parsed.
+	KeyFromOutput
+)
+
+func (this KeySourceKind) String() string {
+	switch this {
+	case KeyFromHostId:
+		return "HostId"
+	case KeyFromTargetId:
+		return "TargetId"
+	case KeyFromLinksId:
+		return "LinksId"
+	case KeyFromArgument:
+		return "Argument"
+	case KeyFromOutput:
+		return "Output"
+	}
+	return "Unknown"
+}
+
+// KeySource maps a primary key field of the parsed element to the job attribute it is
+// set from when the element is created. Fields without a KeySource are left to the rules.
+type KeySource struct {
+	// Field is the Go field name of the primary key, e.g. "ClusterName".
+	Field string
+	Kind  KeySourceKind
+	// Name is the argument name of KeyFromArgument and the attribute PropertyId of
+	// KeyFromOutput.
+	Name string
+}
+
+// defaultKeySources sets the first primary key from the HostId.
+func defaultKeySources(primaryKeys []string) ([]*KeySource, error) {
+	if len(primaryKeys) == 0 {
+		return nil, errors.New("no primary keys to set from the job")
+	}
+	return []*KeySource{{Field: primaryKeys[0], Kind: KeyFromHostId}}, nil
+}
+
+// validateKeySources checks that every key source targets a settable field of elem.
+func validateKeySources(elem interface{}, keySources []*KeySource) error {
+	v := reflect.ValueOf(elem).Elem()
+	for _, source := range keySources {
+		field := v.FieldByName(source.Field)
+		if !field.IsValid() || !field.CanSet() {
+			return errors.New("primary key field " + source.Field + " of " + v.Type().Name() + " cannot be set")
+		}
+		if (source.Kind == KeyFromArgument || source.Kind == KeyFromOutput) && source.Name == "" {
+			return errors.New("primary key field " + source.Field + " from " + source.Kind.String() + " has no name")
+		}
+	}
+	return nil
+}
+
+// value returns the value of the key source for the job.
+func (this *KeySource) value(job *l8tpollaris.CJob) (interface{}, bool) {
+	switch this.Kind {
+	case KeyFromHostId:
+		return job.HostId, true
+	case KeyFromTargetId:
+		return job.TargetId, true
+	case KeyFromLinksId:
+		return job.LinksId, true
+	case KeyFromArgument:
+		value, ok := job.Arguments[this.Name]
+		return value, ok
+	}
+	return nil, false
+}
+
+// SetKeys sets the key source fields of elem from the job. It returns an error instead
+// of panicking when a field is missing, cannot be set or has no value in the job.
+// The KeyFromOutput fields are left to SetParsedKeys.
+func SetKeys(elem interface{}, job *l8tpollaris.CJob, keySources []*KeySource) error {
+	for _, source := range keySources {
+		if source.Kind == KeyFromOutput {
+			continue
+		}
+		value, ok := source.value(job)
+		if !ok {
+			return errors.New("no " + source.Kind.String() + " " + source.Name + " for primary key field " +
+				source.Field + " in job " + job.PollarisName + " - " + job.JobName)
+		}
+		err := setKey(elem, source, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetParsedKeys sets the KeyFromOutput fields of elem from the attribute outputs of the
+// parse result. It returns an error when the attribute produced no value.
+func SetParsedKeys(elem interface{}, result *ParseResult, keySources []*KeySource) error {
+	for _, source := range keySources {
+		if source.Kind != KeyFromOutput {
+			continue
+		}
+		value, ok := result.Output(source.Name)
+		if !ok {
+			return errors.New("no " + source.Kind.String() + " " + source.Name + " for primary key field " +
+				source.Field + " in the parse result")
+		}
+		err := setKey(elem, source, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setKey sets the field of the key source to value, converting it when its type differs.
+func setKey(elem interface{}, source *KeySource, value interface{}) error {
+	field := reflect.ValueOf(elem).Elem().FieldByName(source.Field)
+	if !field.IsValid() || !field.CanSet() {
+		return errors.New("cannot set primary key field " + source.Field)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return nil
+	}
+	if !setKeyField(field, fmt.Sprint(value)) {
+		return errors.New("cannot set primary key field " + source.Field + " of type " +
+			field.Type().String() + " to '" + fmt.Sprint(value) + "'")
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
+)
+
+// The dimensions the parser metrics are aggregated by.
+const (
+	MetricsByPollaris = "pollaris"
+	MetricsByJob      = "job"
+	MetricsByRule     = "rule"
+	MetricsByTarget   = "target"
+)
+
+// LatencyBuckets are the upper bounds of the latency histogram buckets.
+// Durations above the last bound are counted in an extra overflow bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second, 5 * time.Second,
+}
+
+// Histogram counts durations into the LatencyBuckets.
+type Histogram struct {
+	// Buckets holds one count per LatencyBuckets bound, plus the overflow bucket.
+	Buckets []int64
+	Count   int64
+	Sum     time.Duration
+	Max     time.Duration
+}
+
+func newHistogram() *Histogram {
+	return &Histogram{Buckets: make([]int64, len(LatencyBuckets)+1)}
+}
+
+func (this *Histogram) observe(d time.Duration) {
+	i := sort.Search(len(LatencyBuckets), func(i int) bool {
+		return d <= LatencyBuckets[i]
+	})
+	this.Buckets[i]++
+	this.Count++
+	this.Sum += d
+	if d > this.Max {
+		this.Max = d
+	}
+}
+
+// Mean returns the average observed duration.
+func (this *Histogram) Mean() time.Duration {
+	if this.Count == 0 {
+		return 0
+	}
+	return this.Sum / time.Duration(this.Count)
+}
+
+func (this *Histogram) clone() *Histogram {
+	clone := *this
+	clone.Buckets = append([]int64{}, this.Buckets...)
+	return &clone
+}
+
+// Counters are the metrics of a single pollaris, job, rule or target.
+// The job counters are not used for rules, and the rule counters only for rules.
+type Counters struct {
+	JobsReceived int64
+	JobsParsed   int64
+	JobsFailed   int64
+	// JobsStale and JobsUnchanged count the jobs skipped by the JobTracker.
+	JobsStale     int64
+	JobsUnchanged int64
+	Patches       int64
+	Deletes       int64
+	Instances     int64
+	// RuleExecutions and RuleFailures count rule executions; for pollaris, job and target
+	// they sum the executions of all their rules.
+	RuleExecutions int64
+	RuleFailures   int64
+	// Latency is the parse duration of the jobs, or the execution duration of the rule.
+	Latency *Histogram
+}
+
+func newCounters() *Counters {
+	return &Counters{Latency: newHistogram()}
+}
+
+func (this *Counters) clone() *Counters {
+	clone := *this
+	clone.Latency = this.Latency.clone()
+	return &clone
+}
+
+// Metrics aggregates the parser counters and latency histograms by pollaris, job
+// (pollaris.job), rule and target. It is safe for concurrent use.
+type Metrics struct {
+	mtx      *sync.Mutex
+	started  time.Time
+	counters map[string]map[string]*Counters
+}
+
+// NewMetrics creates an empty Metrics.
+func NewMetrics() *Metrics {
+	metrics := &Metrics{mtx: &sync.Mutex{}, started: time.Now()}
+	metrics.counters = make(map[string]map[string]*Counters)
+	for _, dimension := range []string{MetricsByPollaris, MetricsByJob, MetricsByRule, MetricsByTarget} {
+		metrics.counters[dimension] = make(map[string]*Counters)
+	}
+	return metrics
+}
+
+// Started returns the time the metrics started counting.
+func (this *Metrics) Started() time.Time {
+	return this.started
+}
+
+// JobReceived counts a job received by the service.
+func (this *Metrics) JobReceived(job *l8tpollaris.CJob) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, c := range this.jobCounters(job) {
+		c.JobsReceived++
+	}
+}
+
+// JobFailed counts a job that could not be parsed at all, e.g. a job reporting a
+// collection error, an unknown poll or a fatal parse error.
+func (this *Metrics) JobFailed(job *l8tpollaris.CJob) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, c := range this.jobCounters(job) {
+		c.JobsFailed++
+	}
+}
+
+// JobSkipped counts a job that was not parsed because of the JobTracker decision.
+func (this *Metrics) JobSkipped(job *l8tpollaris.CJob, decision JobDecision) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, c := range this.jobCounters(job) {
+		switch decision {
+		case JobStale:
+			c.JobsStale++
+		case JobUnchanged:
+			c.JobsUnchanged++
+		}
+	}
+}
+
+// JobParsed records the outcome of parsing a job. A job whose parse failed fatally is
+// counted as failed, otherwise as parsed; the rule counters are updated either way.
+func (this *Metrics) JobParsed(job *l8tpollaris.CJob, result *ParseResult, err error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	executions, failures := int64(0), int64(0)
+	if result != nil {
+		for _, attr := range result.Attributes {
+			for i, ruleName := range attr.Rules {
+				c := this.counter(MetricsByRule, ruleName)
+				c.RuleExecutions++
+				if i < len(attr.RuleDurations) {
+					c.Latency.observe(attr.RuleDurations[i])
+				}
+				executions++
+			}
+			if attr.FailedRule != "" {
+				this.counter(MetricsByRule, attr.FailedRule).RuleFailures++
+				failures++
+			}
+		}
+	}
+
+	for _, c := range this.jobCounters(job) {
+		c.RuleExecutions += executions
+		c.RuleFailures += failures
+		if err != nil && !IsPartial(err) {
+			c.JobsFailed++
+			continue
+		}
+		c.JobsParsed++
+		if result != nil {
+			c.Instances += int64(len(result.Instances))
+			c.Latency.observe(result.Duration)
+		}
+	}
+}
+
+// Patched counts the PATCH elements sent to the inventory for a job.
+func (this *Metrics) Patched(job *l8tpollaris.CJob, count int) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, c := range this.jobCounters(job) {
+		c.Patches += int64(count)
+	}
+}
+
+// Deleted counts the DELETE elements sent to the inventory for a job.
+func (this *Metrics) Deleted(job *l8tpollaris.CJob, count int) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, c := range this.jobCounters(job) {
+		c.Deletes += int64(count)
+	}
+}
+
+// Counters returns a copy of the counters of a key of a dimension, or nil if none.
+func (this *Metrics) Counters(dimension, key string) *Counters {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	c, ok := this.counters[dimension][key]
+	if !ok {
+		return nil
+	}
+	return c.clone()
+}
+
+// Snapshot returns a copy of all the counters, by dimension and key.
+func (this *Metrics) Snapshot() map[string]map[string]*Counters {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	result := make(map[string]map[string]*Counters, len(this.counters))
+	for dimension, byKey := range this.counters {
+		result[dimension] = make(map[string]*Counters, len(byKey))
+		for key, c := range byKey {
+			result[dimension][key] = c.clone()
+		}
+	}
+	return result
+}
+
+// metricsColumns are the leading columns of the Table rows, followed by one column
+// per latency bucket.
+var metricsColumns = []string{"dimension", "key", "received", "parsed", "failed", "stale", "unchanged", "patches", "deletes",
+	"instances", "rule_executions", "rule_failures", "latency_count", "latency_mean_ms", "latency_max_ms"}
+
+// Table renders the metrics as a CTable, one row per dimension and key sorted by both,
+// so they can be returned by the service Get and displayed as is.
+func (this *Metrics) Table() *l8tpollaris.CTable {
+	snapshot := this.Snapshot()
+	table := &l8tpollaris.CTable{}
+	table.Columns = make(map[int32]string)
+	for i, name := range metricsColumns {
+		table.Columns[int32(i)] = name
+	}
+	for i, bound := range LatencyBuckets {
+		table.Columns[int32(len(metricsColumns)+i)] = "le_" + bound.String()
+	}
+	table.Columns[int32(len(metricsColumns)+len(LatencyBuckets))] = "le_inf"
+
+	dimensions := make([]string, 0, len(snapshot))
+	for dimension := range snapshot {
+		dimensions = append(dimensions, dimension)
+	}
+	sort.Strings(dimensions)
+
+	table.Rows = make(map[int32]*l8tpollaris.CRow)
+	for _, dimension := range dimensions {
+		keys := make([]string, 0, len(snapshot[dimension]))
+		for key := range snapshot[dimension] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			c := snapshot[dimension][key]
+			values := []interface{}{dimension, key, c.JobsReceived, c.JobsParsed, c.JobsFailed, c.JobsStale, c.JobsUnchanged, c.Patches, c.Deletes,
+				c.Instances, c.RuleExecutions, c.RuleFailures, c.Latency.Count,
+				c.Latency.Mean().Milliseconds(), c.Latency.Max.Milliseconds()}
+			for _, count := range c.Latency.Buckets {
+				values = append(values, count)
+			}
+			row := &l8tpollaris.CRow{Data: make(map[int32][]byte, len(values))}
+			for i, value := range values {
+				enc := object.NewEncode()
+				enc.Add(value)
+				row.Data[int32(i)] = enc.Data()
+			}
+			table.Rows[int32(len(table.Rows))] = row
+		}
+	}
+	return table
+}
+
+// jobCounters returns the pollaris, job and target counters of a job.
+func (this *Metrics) jobCounters(job *l8tpollaris.CJob) []*Counters {
+	return []*Counters{
+		this.counter(MetricsByPollaris, job.PollarisName),
+		this.counter(MetricsByJob, job.PollarisName+"."+job.JobName),
+		this.counter(MetricsByTarget, job.TargetId),
+	}
+}
+
+func (this *Metrics) counter(dimension, key string) *Counters {
+	c, ok := this.counters[dimension][key]
+	if !ok {
+		c = newCounters()
+		this.counters[dimension][key] = c
+	}
+	return c
+}
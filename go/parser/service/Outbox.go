@@ -0,0 +1,204 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// DefaultOutboxBatch is the number of pending elements that triggers sending them.
+const DefaultOutboxBatch = 30
+
+// DefaultOutboxInterval is how long an element waits in the outbox before it is sent.
+const DefaultOutboxInterval = 5 * time.Second
+
+// ErrOutboxClosed is returned by Outbox.Add once the outbox was closed.
+var ErrOutboxClosed = errors.New("outbox is closed")
+
+// Sender sends a batch of elements to the leader of a service, e.g. the inventory cache.
+type Sender func(serviceName string, serviceArea byte, action ifs.Action, elements []interface{}) error
+
+// outboxEntry is a pending element with its destination and action.
+type outboxEntry struct {
+	elem        interface{}
+	serviceName string
+	serviceArea byte
+	action      ifs.Action
+}
+
+// Outbox batches the elements sent to the inventory. The pending elements are sent when
+// there are batch of them or they have waited for the interval, and Flush sends them on
+// demand, so no element is left behind at shutdown. Elements are sent in the order they
+// were added, consecutive elements of the same service and action in one batch, so a
+// PATCH is never overtaken by a later DELETE. The elements that are not sent, because
+// their batch failed, they were discarded or added after Close, are counted per service
+// and action, see Dropped. It is safe for concurrent use.
+type Outbox struct {
+	send     Sender
+	batch    int
+	mtx      *sync.Mutex
+	pending  []*outboxEntry
+	closed   bool
+	dropped  map[string]int
+	sendMtx  *sync.Mutex
+	stop     chan struct{}
+	stopOnce *sync.Once
+}
+
+// NewOutbox creates an Outbox sending with send, and starts sending the pending batches
+// every interval. Zero batch uses DefaultOutboxBatch and zero interval DefaultOutboxInterval.
+func NewOutbox(send Sender, batch int, interval time.Duration) *Outbox {
+	if batch <= 0 {
+		batch = DefaultOutboxBatch
+	}
+	if interval <= 0 {
+		interval = DefaultOutboxInterval
+	}
+	this := &Outbox{send: send, batch: batch, mtx: &sync.Mutex{}, dropped: make(map[string]int),
+		sendMtx: &sync.Mutex{}, stop: make(chan struct{}), stopOnce: &sync.Once{}}
+	go this.watch(interval)
+	return this
+}
+
+// Add queues an element for the leader of the given service, sending the pending
+// elements when there are batch of them. Once the outbox is closed the element is
+// dropped and ErrOutboxClosed is returned.
+func (this *Outbox) Add(elem interface{}, serviceName string, serviceArea byte, action ifs.Action) error {
+	this.mtx.Lock()
+	if this.closed {
+		this.dropped[droppedKey(serviceName, serviceArea, action)]++
+		this.mtx.Unlock()
+		return ErrOutboxClosed
+	}
+	this.pending = append(this.pending, &outboxEntry{elem: elem, serviceName: serviceName,
+		serviceArea: serviceArea, action: action})
+	full := len(this.pending) >= this.batch
+	this.mtx.Unlock()
+	if full {
+		this.Flush()
+	}
+	return nil
+}
+
+// Pending returns the number of elements not sent yet.
+func (this *Outbox) Pending() int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return len(this.pending)
+}
+
+// Flush sends every pending element and returns the first error. The elements of a
+// failed batch are not retried, they are counted as dropped.
+func (this *Outbox) Flush() error {
+	// One flush at a time, so the batches of two flushes are not interleaved
+	this.sendMtx.Lock()
+	defer this.sendMtx.Unlock()
+	this.mtx.Lock()
+	pending := this.pending
+	this.pending = nil
+	this.mtx.Unlock()
+
+	var result error
+	for start := 0; start < len(pending); {
+		first := pending[start]
+		elements := []interface{}{first.elem}
+		end := start + 1
+		for ; end < len(pending); end++ {
+			next := pending[end]
+			if next.serviceName != first.serviceName || next.serviceArea != first.serviceArea || next.action != first.action {
+				break
+			}
+			elements = append(elements, next.elem)
+		}
+		err := this.send(first.serviceName, first.serviceArea, first.action, elements)
+		if err != nil {
+			this.mtx.Lock()
+			this.dropped[droppedKey(first.serviceName, first.serviceArea, first.action)] += len(elements)
+			this.mtx.Unlock()
+			if result == nil {
+				result = err
+			}
+		}
+		start = end
+	}
+	return result
+}
+
+// Close stops the periodic sending and rejects the elements added from now on. Pending
+// elements are kept until the next Flush.
+func (this *Outbox) Close() {
+	this.mtx.Lock()
+	this.closed = true
+	this.mtx.Unlock()
+	this.stopOnce.Do(func() {
+		close(this.stop)
+	})
+}
+
+// Discard drops the pending elements without sending them, counting them as dropped,
+// and returns how many there were.
+func (this *Outbox) Discard() int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	for _, entry := range this.pending {
+		this.dropped[droppedKey(entry.serviceName, entry.serviceArea, entry.action)]++
+	}
+	discarded := len(this.pending)
+	this.pending = nil
+	return discarded
+}
+
+// Dropped returns the number of elements that were not sent, keyed by service and
+// action, e.g. "NCache/0 PATCH".
+func (this *Outbox) Dropped() map[string]int {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	result := make(map[string]int, len(this.dropped))
+	for key, count := range this.dropped {
+		result[key] = count
+	}
+	return result
+}
+
+// droppedKey names the service and action of dropped elements.
+func droppedKey(serviceName string, serviceArea byte, action ifs.Action) string {
+	name := serviceName + "/" + strconv.Itoa(int(serviceArea)) + " "
+	switch action {
+	case ifs.PATCH:
+		return name + "PATCH"
+	case ifs.DELETE:
+		return name + "DELETE"
+	}
+	return name + strconv.Itoa(int(action))
+}
+
+func (this *Outbox) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.stop:
+			return
+		case <-ticker.C:
+			this.Flush()
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrorPolicy controls how the parser reacts when an attribute's rule fails.
+type ErrorPolicy int
+
+const (
+	// FailFast aborts the job on the first failing attribute. It is the zero value, so
+	// it is the default of every policy that is not set.
+	FailFast ErrorPolicy = iota
+	// ContinueOnError keeps executing the remaining attributes after a failure and
+	// reports all failures together. Attributes marked as required still abort the job.
+	// It must be enabled with Config.ErrorPolicy.
+	ContinueOnError
+)
+
+// String returns the name of the error policy.
+func (this ErrorPolicy) String() string {
+	switch this {
+	case FailFast:
+		return "FailFast"
+	case ContinueOnError:
+		return "ContinueOnError"
+	}
+	return "ErrorPolicy(" + strconv.Itoa(int(this)) + ")"
+}
+
+// AttributeError describes the failure of a single poll attribute.
+type AttributeError struct {
+	PollarisName string
+	JobName      string
+	PropertyId   string
+	// Rule is the name of the rule that failed.
+	Rule string
+	// Required is true when the attribute was marked as required, which makes the failure fatal.
+	Required bool
+	Err      error
+}
+
+func (this *AttributeError) Error() string {
+	buff := strings.Builder{}
+	buff.WriteString(this.PollarisName)
+	buff.WriteString(":")
+	buff.WriteString(this.JobName)
+	buff.WriteString(" attribute ")
+	buff.WriteString(this.PropertyId)
+	if this.Required {
+		buff.WriteString(" (required)")
+	}
+	buff.WriteString(" rule ")
+	buff.WriteString(this.Rule)
+	buff.WriteString(": ")
+	if this.Err != nil {
+		buff.WriteString(this.Err.Error())
+	}
+	return buff.String()
+}
+
+func (this *AttributeError) Unwrap() error {
+	return this.Err
+}
+
+// ParseErrors aggregates the attribute failures of a single job.
+type ParseErrors struct {
+	Errors []*AttributeError
+	// Aborted is true when parsing stopped before all attributes were executed,
+	// either because of the FailFast policy or because a required attribute failed.
+	Aborted bool
+}
+
+func (this *ParseErrors) add(err *AttributeError) {
+	this.Errors = append(this.Errors, err)
+}
+
+// Fatal returns true if parsing was aborted or any of the failed attributes was required,
+// meaning the partial result must not be used.
+func (this *ParseErrors) Fatal() bool {
+	if this.Aborted {
+		return true
+	}
+	for _, err := range this.Errors {
+		if err.Required {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *ParseErrors) Error() string {
+	if len(this.Errors) == 1 {
+		return this.Errors[0].Error()
+	}
+	buff := strings.Builder{}
+	buff.WriteString(strconv.Itoa(len(this.Errors)))
+	buff.WriteString(" attributes failed: ")
+	for i, err := range this.Errors {
+		if i > 0 {
+			buff.WriteString("; ")
+		}
+		buff.WriteString(err.Error())
+	}
+	return buff.String()
+}
+
+func (this *ParseErrors) Unwrap() []error {
+	result := make([]error, len(this.Errors))
+	for i, err := range this.Errors {
+		result[i] = err
+	}
+	return result
+}
+
+// IsPartial returns true if err only reports non-fatal attribute failures, meaning
+// the element was still populated by the attributes that parsed successfully.
+func IsPartial(err error) bool {
+	var parseErrors *ParseErrors
+	if errors.As(err, &parseErrors) {
+		return !parseErrors.Fatal()
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"time"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+)
+
+// AttributeResult records the outcome of executing the rules of a single poll attribute.
+type AttributeResult struct {
+	// PropertyId is the model-specific property path the attribute targets.
+	// It is empty when the attribute has no PropertyId for the job's model.
+	PropertyId string
+	// Rules lists the names of the rules executed for this attribute, in order.
+	Rules []string
+	// RuleDurations holds the time spent in each executed rule, parallel to Rules.
+	RuleDurations []time.Duration
+	// Skipped is true when the attribute was not executed because it does not
+	// define a PropertyId for the job's model.
+	Skipped bool
+	// Error is the error returned by the failing rule, if any.
+	Error error
+	// FailedRule is the name of the failing rule, if any.
+	FailedRule string
+	// Duration is the time spent executing the attribute's rules.
+	Duration time.Duration
+	// Traces holds one trace per executed rule, only populated by Parser.Explain.
+	Traces []*rules.RuleTrace
+	// Output is the value produced by the attribute's rules, nil when they produced none
+	// or failed.
+	Output interface{}
+}
+
+// ParseResult is the structured outcome of running the parsing pipeline on a single job.
+// It is returned by Parser.ParseJob and Parser.ParsePoll so callers do not need to
+// re-implement the decode/lookup/rule loop to find out what happened.
+type ParseResult struct {
+	// Element is the target object populated by the rules.
+	Element interface{}
+	// Instances holds the model instances produced by CTableToInstances, if any.
+	Instances []interface{}
+	// Model is the model name used to select the attributes' PropertyIds.
+	Model string
+	// Attributes holds one entry per poll attribute, in poll order.
+	Attributes []*AttributeResult
+	// Warnings holds non-fatal observations, such as attributes skipped for the model.
+	Warnings []string
+	// DecodeDuration is the time spent deserializing the job result.
+	DecodeDuration time.Duration
+	// Duration is the total time spent in the pipeline.
+	Duration time.Duration
+}
+
+// newParseResult creates an empty ParseResult for the given element and model.
+func newParseResult(elem interface{}, modelName string) *ParseResult {
+	return &ParseResult{
+		Element:    elem,
+		Model:      modelName,
+		Attributes: make([]*AttributeResult, 0),
+		Warnings:   make([]string, 0),
+	}
+}
+
+// Executed returns the number of attributes whose rules were executed.
+func (this *ParseResult) Executed() int {
+	count := 0
+	for _, attr := range this.Attributes {
+		if !attr.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// Output returns the value produced by the rules of the executed attribute with the
+// given PropertyId, false when there is no such attribute or it produced no value.
+func (this *ParseResult) Output(propertyId string) (interface{}, bool) {
+	for _, attr := range this.Attributes {
+		if !attr.Skipped && attr.PropertyId == propertyId && attr.Output != nil {
+			return attr.Output, true
+		}
+	}
+	return nil, false
+}
@@ -21,7 +21,10 @@ package service
 
 import (
 	"errors"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/saichler/l8parser/go/parser/rules"
 	"github.com/saichler/l8pollaris/go/pollaris"
@@ -48,6 +51,18 @@ func RegisterRule(rule rules.ParsingRule) {
 	Parser.rules[rule.Name()] = rule
 }
 
+// RuleSchemas returns the parameter schema of every registered rule implementing
+// rules.SchemaRule, keyed by rule name, for documentation and pollaris authoring tools.
+func (this *_Parser) RuleSchemas() map[string][]*rules.ParamSpec {
+	result := make(map[string][]*rules.ParamSpec)
+	for name, rule := range this.rules {
+		if schemaRule, ok := rule.(rules.SchemaRule); ok {
+			result[name] = schemaRule.ParamSchema()
+		}
+	}
+	return result
+}
+
 // newParser creates and initializes a new Parser instance with all registered parsing rules.
 func newParser() *_Parser {
 	p := &_Parser{}
@@ -88,6 +103,16 @@ func newParser() *_Parser {
 	p.rules[restGpuParse.Name()] = restGpuParse
 	cTableToInstances := &rules.CTableToInstances{}
 	p.rules[cTableToInstances.Name()] = cTableToInstances
+	regexExtract := &rules.RegexExtract{}
+	p.rules[regexExtract.Name()] = regexExtract
+	sysDescrParse := &rules.SysDescrParse{}
+	p.rules[sysDescrParse.Name()] = sysDescrParse
+	oidToVendor := &rules.OidToVendor{}
+	p.rules[oidToVendor.Name()] = oidToVendor
+	snmpLldpToLinks := &rules.SnmpLldpToLinks{}
+	p.rules[snmpLldpToLinks.Name()] = snmpLldpToLinks
+	snmpCdpToLinks := &rules.SnmpCdpToLinks{}
+	p.rules[snmpCdpToLinks.Name()] = snmpCdpToLinks
 	return p
 }
 
@@ -96,6 +121,85 @@ func newParser() *_Parser {
 // and executes each rule defined in the poll's attributes to transform the data.
 // Parameters: job (completed collection job), any (target object to populate), resources (system resources).
 func (this *_Parser) Parse(job *l8tpollaris.CJob, any interface{}, resources ifs.IResources) error {
+	_, err := this.ParseJob(job, any, resources)
+	return err
+}
+
+// ParseMulti executes parsing rules and returns any multi-instance output from CTableToInstances.
+func (this *_Parser) ParseMulti(job *l8tpollaris.CJob, any interface{}, resources ifs.IResources) ([]interface{}, error) {
+	result, err := this.ParseJob(job, any, resources)
+	if err != nil {
+		return nil, err
+	}
+	return result.Instances, nil
+}
+
+// ParseJob runs the parsing pipeline for a completed collection job, looking up the poll
+// configuration by the job's pollaris and job names and the model by the job's links ID.
+// The returned ParseResult is non-nil whenever the job result was decoded, even if a rule failed.
+func (this *_Parser) ParseJob(job *l8tpollaris.CJob, elem interface{}, resources ifs.IResources) (*ParseResult, error) {
+	err := validateJob(job, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	poll, err := pollaris.Poll(job.PollarisName, job.JobName, resources)
+	if err != nil {
+		return nil, resources.Logger().Error("cannot find poll for polaris ", job.PollarisName, ":", job.JobName)
+	}
+	return this.parse(job, poll, targets.Links.Model(job.LinksId), elem, resources, FailFast, false)
+}
+
+// ParsePoll runs the parsing pipeline for a job against an explicit poll and model name.
+// This is the entry point for callers that already resolved the poll, or that parse
+// outside of a running pollaris/targets setup (e.g. replaying persisted jobs).
+// It fails fast on the first failing attribute.
+func (this *_Parser) ParsePoll(job *l8tpollaris.CJob, poll *l8tpollaris.L8Poll, modelName string,
+	elem interface{}, resources ifs.IResources) (*ParseResult, error) {
+	return this.ParsePollWithPolicy(job, poll, modelName, elem, resources, FailFast)
+}
+
+// ParsePollWithPolicy is ParsePoll with the error policy of the caller, e.g. the
+// Config.ErrorPolicy of a ParsingService.
+func (this *_Parser) ParsePollWithPolicy(job *l8tpollaris.CJob, poll *l8tpollaris.L8Poll, modelName string,
+	elem interface{}, resources ifs.IResources, policy ErrorPolicy) (*ParseResult, error) {
+	err := validateJob(job, resources)
+	if err != nil {
+		return nil, err
+	}
+	return this.parse(job, poll, modelName, elem, resources, policy, false)
+}
+
+// Explain runs the parsing pipeline for a job in explain mode. Every attribute is executed
+// regardless of the error policy, and each attribute result carries a rules.RuleTrace per
+// executed rule: the PropertyId after index/key injection, the raw extracted value, the
+// coerced value and whether setting the property succeeded. Only elem is modified, nothing
+// is sent to the inventory, so it is safe to call on a live job to find out why a field is missing.
+func (this *_Parser) Explain(job *l8tpollaris.CJob, elem interface{}, resources ifs.IResources) (*ParseResult, error) {
+	err := validateJob(job, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	poll, err := pollaris.Poll(job.PollarisName, job.JobName, resources)
+	if err != nil {
+		return nil, resources.Logger().Error("cannot find poll for polaris ", job.PollarisName, ":", job.JobName)
+	}
+	return this.ExplainPoll(job, poll, targets.Links.Model(job.LinksId), elem, resources)
+}
+
+// ExplainPoll is Explain against an explicit poll and model name.
+func (this *_Parser) ExplainPoll(job *l8tpollaris.CJob, poll *l8tpollaris.L8Poll, modelName string,
+	elem interface{}, resources ifs.IResources) (*ParseResult, error) {
+	err := validateJob(job, resources)
+	if err != nil {
+		return nil, err
+	}
+	return this.parse(job, poll, modelName, elem, resources, FailFast, true)
+}
+
+// validateJob verifies that the job completed without error and carries a decodable result.
+func validateJob(job *l8tpollaris.CJob, resources ifs.IResources) error {
 	if job.Error != "" {
 		return errors.New(job.Error)
 	}
@@ -104,34 +208,45 @@ func (this *_Parser) Parse(job *l8tpollaris.CJob, any interface{}, resources ifs
 		return resources.Logger().Error("Invalid job result ", job.TargetId, " - ", job.PollarisName,
 			" - ", job.JobName, " - ", string(job.Result))
 	}
+	return nil
+}
+
+// parse is the single execution pipeline shared by ParseJob and ParsePoll.
+// It decodes the job result, then executes the rules of every attribute that
+// defines a PropertyId for the given model, recording each outcome in the result.
+// Attribute failures are aggregated into a *ParseErrors according to the error policy.
+func (this *_Parser) parse(job *l8tpollaris.CJob, poll *l8tpollaris.L8Poll, modelName string,
+	elem interface{}, resources ifs.IResources, policy ErrorPolicy, explain bool) (*ParseResult, error) {
+	start := time.Now()
+	result := newParseResult(elem, modelName)
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
 
-	workSpace := make(map[string]interface{})
 	enc := object.NewDecode(job.Result, 0, resources.Registry())
 	data, err := enc.Get()
+	result.DecodeDuration = time.Since(start)
 	if err != nil {
-		return resources.Logger().Error(err)
+		return nil, resources.Logger().Error(err)
 	}
 
-	poll, err := pollaris.Poll(job.PollarisName, job.JobName, resources)
-	if err != nil {
-		return resources.Logger().Error("cannot find poll for polaris ", job.PollarisName, ":", job.JobName)
-	}
-	workSpace[rules.Input] = data
-	workSpace[rules.JobEnded] = job.Ended
 	// Workspace key is named TargetId for historical reasons but holds the
 	// HostId — i.e. the cluster/device identity used as the cluster-name
 	// primary key on parsed instances. TargetId may be unique-per-link in
 	// multi-target-per-host setups (probler K8s) and would corrupt the
 	// resulting instance's ClusterName field.
-	workSpace[rules.TargetId] = job.HostId
+	jobWorkSpace := rules.NewJobWorkspace(data, job.Ended, job.HostId)
 	if poll.Attributes == nil {
-		return resources.Logger().Error("No attributes are defined on pollaris "+job.PollarisName, ":", job.JobName)
+		return result, resources.Logger().Error("No attributes are defined on pollaris "+job.PollarisName, ":", job.JobName)
 	}
 
-	modelName := targets.Links.Model(job.LinksId)
+	parseErrors := &ParseErrors{}
 	for _, attr := range poll.Attributes {
+		attrResult := &AttributeResult{}
+		result.Attributes = append(result.Attributes, attrResult)
 		propertyId, ok := attr.PropertyId[modelName]
 		if !ok {
+			attrResult.Skipped = true
 			availableModels := make([]string, 0, len(attr.PropertyId))
 			for k, v := range attr.PropertyId {
 				suffix := v
@@ -140,90 +255,104 @@ func (this *_Parser) Parse(job *l8tpollaris.CJob, any interface{}, resources ifs
 				}
 				availableModels = append(availableModels, k+"="+suffix)
 			}
-			resources.Logger().Debug("No propertyId for model '", modelName, "' in pollaris ", job.PollarisName, ":", job.JobName, " available models: ", availableModels)
+			sort.Strings(availableModels)
+			warning := "No propertyId for model '" + modelName + "' in pollaris " + job.PollarisName + ":" +
+				job.JobName + " available models: " + strings.Join(availableModels, ", ")
+			result.Warnings = append(result.Warnings, warning)
+			resources.Logger().Debug(warning)
 			continue
 		}
-		workSpace[rules.PropertyId] = propertyId
-		for _, rData := range attr.Rules {
-			if rData.Params != nil {
-				for p, v := range rData.Params {
-					workSpace[p] = v.Value
-				}
-			}
-			ruleImpl, ok := this.rules[rData.Name]
-			if !ok {
-				return resources.Logger().Error("Cannot find parsing rule ", rData.Name)
-			}
-			err = ruleImpl.Parse(resources, workSpace, rData.Params, any, poll.What)
-			if err != nil {
-				return err
+		attrResult.PropertyId = propertyId
+		workSpace := rules.NewAttributeWorkspace(jobWorkSpace, propertyId)
+		ruleName, err := this.parseAttribute(attr, workSpace, attrResult, elem, poll.What, resources, explain)
+		if instances, ok := workSpace[rules.Instances].([]interface{}); ok {
+			result.Instances = append(result.Instances, instances...)
+		}
+		if err != nil {
+			attrResult.FailedRule = ruleName
+			attrErr := &AttributeError{PollarisName: job.PollarisName, JobName: job.JobName,
+				PropertyId: propertyId, Rule: ruleName, Required: isRequired(attr), Err: err}
+			parseErrors.add(attrErr)
+			if !explain && (policy == FailFast || attrErr.Required) {
+				parseErrors.Aborted = true
+				return result, parseErrors
 			}
 		}
 	}
-	return nil
-}
-
-// ParseMulti executes parsing rules and returns any multi-instance output from CTableToInstances.
-// Returns the workspace so the caller can check for the Instances key.
-func (this *_Parser) ParseMulti(job *l8tpollaris.CJob, any interface{}, resources ifs.IResources) ([]interface{}, error) {
-	if job.Error != "" {
-		return nil, errors.New(job.Error)
+	if len(parseErrors.Errors) > 0 {
+		return result, parseErrors
 	}
+	return result, nil
+}
 
-	if job.Result == nil || len(job.Result) < 4 {
-		return nil, resources.Logger().Error("Invalid job result ", job.TargetId, " - ", job.PollarisName,
-			" - ", job.JobName, " - ", string(job.Result))
-	}
+// parseAttribute executes the rules of a single attribute in order within the attribute's
+// own workspace, stopping at the first failure and returning the name of the failing rule.
+func (this *_Parser) parseAttribute(attr *l8tpollaris.L8PAttribute, workSpace map[string]interface{},
+	attrResult *AttributeResult, elem interface{}, what string, resources ifs.IResources, explain bool) (string, error) {
+	start := time.Now()
+	defer func() {
+		attrResult.Duration = time.Since(start)
+	}()
 
-	workSpace := make(map[string]interface{})
-	enc := object.NewDecode(job.Result, 0, resources.Registry())
-	data, err := enc.Get()
-	if err != nil {
-		return nil, resources.Logger().Error(err)
+	for _, rData := range attr.Rules {
+		rules.ApplyRuleParams(workSpace, rData.Params)
+		ruleImpl, ok := this.rules[rData.Name]
+		if !ok {
+			attrResult.Error = resources.Logger().Error("Cannot find parsing rule ", rData.Name)
+			return rData.Name, attrResult.Error
+		}
+		attrResult.Rules = append(attrResult.Rules, rData.Name)
+		var trace *rules.RuleTrace
+		if explain {
+			trace = &rules.RuleTrace{Rule: rData.Name}
+			workSpace[rules.TraceKey] = trace
+			attrResult.Traces = append(attrResult.Traces, trace)
+		}
+		// Rules describing their params get them pre-parsed, scoped to this rule only
+		delete(workSpace, rules.TypedParamsKey)
+		if schemaRule, ok := ruleImpl.(rules.SchemaRule); ok {
+			typed, err := rules.ParseParams(schemaRule.ParamSchema(), rData.Params)
+			if err != nil {
+				attrResult.Error = errors.New(rData.Name + ": " + err.Error())
+				attrResult.RuleDurations = append(attrResult.RuleDurations, 0)
+				traceError(trace, attrResult.Error)
+				return rData.Name, attrResult.Error
+			}
+			workSpace[rules.TypedParamsKey] = typed
+		}
+		ruleStart := time.Now()
+		err := ruleImpl.Parse(resources, workSpace, rData.Params, elem, what)
+		attrResult.RuleDurations = append(attrResult.RuleDurations, time.Since(ruleStart))
+		if err != nil {
+			attrResult.Error = err
+			traceError(trace, err)
+			return rData.Name, err
+		}
 	}
+	attrResult.Output = workSpace[rules.Output]
+	return "", nil
+}
 
-	poll, err := pollaris.Poll(job.PollarisName, job.JobName, resources)
-	if err != nil {
-		return nil, resources.Logger().Error("cannot find poll for polaris ", job.PollarisName, ":", job.JobName)
-	}
-	workSpace[rules.Input] = data
-	workSpace[rules.JobEnded] = job.Ended
-	// Workspace key is named TargetId for historical reasons but holds the
-	// HostId — i.e. the cluster/device identity used as the cluster-name
-	// primary key on parsed instances. TargetId may be unique-per-link in
-	// multi-target-per-host setups (probler K8s) and would corrupt the
-	// resulting instance's ClusterName field.
-	workSpace[rules.TargetId] = job.HostId
-	if poll.Attributes == nil {
-		return nil, resources.Logger().Error("No attributes are defined on pollaris " + job.PollarisName + ":" + job.JobName)
+// traceError records a rule error on the trace when running in explain mode.
+func traceError(trace *rules.RuleTrace, err error) {
+	if trace != nil {
+		trace.Error = err.Error()
 	}
+}
 
-	modelName := targets.Links.Model(job.LinksId)
-	for _, attr := range poll.Attributes {
-		propertyId, ok := attr.PropertyId[modelName]
-		if !ok {
+// isRequired returns true if any rule of the attribute sets the "required" parameter to true.
+// A failing required attribute aborts the job regardless of the error policy.
+func isRequired(attr *l8tpollaris.L8PAttribute) bool {
+	for _, rData := range attr.Rules {
+		if rData.Params == nil {
 			continue
 		}
-		workSpace[rules.PropertyId] = propertyId
-		for _, rData := range attr.Rules {
-			if rData.Params != nil {
-				for p, v := range rData.Params {
-					workSpace[p] = v.Value
-				}
-			}
-			ruleImpl, ok := this.rules[rData.Name]
-			if !ok {
-				return nil, resources.Logger().Error("Cannot find parsing rule ", rData.Name)
-			}
-			err = ruleImpl.Parse(resources, workSpace, rData.Params, any, poll.What)
-			if err != nil {
-				return nil, err
+		if param, ok := rData.Params[rules.Required]; ok && param != nil {
+			required, err := strconv.ParseBool(param.Value)
+			if err == nil && required {
+				return true
 			}
 		}
 	}
-
-	if instances, ok := workSpace[rules.Instances].([]interface{}); ok {
-		return instances, nil
-	}
-	return nil, nil
+	return false
 }
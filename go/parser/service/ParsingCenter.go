@@ -16,8 +16,9 @@ limitations under the License.
 package service
 
 import (
-	"fmt"
+	"errors"
 	"reflect"
+	"strings"
 
 	"github.com/saichler/l8pollaris/go/pollaris"
 	"github.com/saichler/l8pollaris/go/pollaris/targets"
@@ -27,92 +28,242 @@ import (
 )
 
 // createElementInstance creates a new instance of the configured element type
-// and initializes its primary key field with the job's host ID.
+// and initializes its primary key fields from the job, as mapped by the key sources.
+// By default the first primary key is set from the job's host ID.
 //
 // HostId is used (not TargetId) so that callers can post multiple targets per
 // host without the host's identifying primary key (e.g. ClusterName for K8s
 // resources) inheriting per-target uniqueness suffixes. For setups where one
 // target == one host, HostId == TargetId and behavior is unchanged.
-func (this *ParsingService) createElementInstance(job *l8tpollaris.CJob) interface{} {
-	newElem := reflect.New(reflect.ValueOf(this.elem).Elem().Type())
-	field := newElem.Elem().FieldByName(this.primaryKey)
-	if !field.CanSet() {
-		panic("cannot set field " + this.primaryKey)
-	}
-	field.Set(reflect.ValueOf(job.HostId))
-	return newElem.Interface()
+func (this *ParsingService) createElementInstance(job *l8tpollaris.CJob) (interface{}, error) {
+	newElem := reflect.New(reflect.ValueOf(this.elem).Elem().Type()).Interface()
+	err := SetKeys(newElem, job, this.keySources)
+	if err != nil {
+		return nil, err
+	}
+	return newElem, nil
 }
 
 // JobComplete is called when a collection job completes. It parses the job results
 // using the Parser, creates an element instance, and sends the parsed data to the
 // inventory cache service via PATCH operation.
 // For polls using CTableToInstances, it sends each created instance individually.
+// When some attributes fail without aborting the job (see Config.ErrorPolicy), the failures
+// are logged and the partially populated element is still sent.
+// Jobs older than the last applied job of the same host, pollaris and job, and jobs
+// whose result did not change, are skipped (see JobTracker). With Config.ReconcileTables,
+// components missing from a table poll are sent marked as not present (see Reconciler),
+// and instances missing from a CTableToInstances poll are deleted (see InstanceTracker).
+// Jobs that fail to parse are kept in the dead letters for Reparse.
 func (this *ParsingService) JobComplete(job *l8tpollaris.CJob, resources ifs.IResources) {
 	poll, err := pollaris.Poll(job.PollarisName, job.JobName, resources)
 	if err != nil {
+		this.metrics.JobFailed(job)
+		this.deadLetters.Add(job, err)
 		resources.Logger().Error("ParsingCenter:" + err.Error())
 		return
 	}
 
 	if job.Error != "" {
+		this.metrics.JobFailed(job)
 		resources.Logger().Error("ParsingCenter: job error = ", job.Error)
 		return
 	}
 
 	if job.Error == "" && poll.Attributes != nil {
-		elem := this.createElementInstance(job)
-		instances, err := Parser.ParseMulti(job, elem, resources)
+		decision := this.tracker.Check(job)
+		if decision != JobApply {
+			this.metrics.JobSkipped(job, decision)
+			resources.Logger().Debug("ParsingCenter: skipping ", decision.String(), " job ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName)
+			return
+		}
+		elem, err := this.createElementInstance(job)
+		if err != nil {
+			this.tracker.Forget(job)
+			this.metrics.JobFailed(job)
+			this.deadLetters.Add(job, err)
+			resources.Logger().Error("ParsingCenter.JobComplete: ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName, " - ", err.Error())
+			return
+		}
+		result, err := Parser.ParsePollWithPolicy(job, poll, targets.Links.Model(job.LinksId), elem, resources,
+			this.config.ErrorPolicy)
+		this.metrics.JobParsed(job, result, err)
 		if err != nil {
 			resources.Logger().Error("ParsingCenter.JobComplete: ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName, " - ", err.Error())
+			// Non-fatal attribute failures still PATCH whatever the other attributes parsed.
+			if !IsPartial(err) {
+				// Let the next job of the key through even if its result is the same
+				this.tracker.Forget(job)
+				this.deadLetters.Add(job, err)
+				return
+			}
+		}
+		keyErr := SetParsedKeys(elem, result, this.keySources)
+		if keyErr != nil {
+			this.tracker.Forget(job)
+			this.metrics.JobFailed(job)
+			this.deadLetters.Add(job, keyErr)
+			resources.Logger().Error("ParsingCenter.JobComplete: ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName, " - ", keyErr.Error())
 			return
 		}
+		this.deadLetters.Remove(job)
 		if this.vnic == nil {
+			this.tracker.Forget(job)
 			resources.Logger().Error("No Vnic to notify inventory")
 			return
 		}
 
+		// Only a complete table walk is authoritative for the collections it populates
+		if this.reconciler != nil && err == nil && len(result.Instances) == 0 &&
+			poll.Operation == l8tpollaris.L8C_Operation_L8C_Table {
+			marked := this.reconciler.Reconcile(this.objectKey(job, elem), elem)
+			if marked > 0 {
+				resources.Logger().Debug("ParsingCenter: ", marked, " components of ", job.TargetId, " - ",
+					job.PollarisName, " - ", job.JobName, " are no longer present")
+			}
+		}
+
 		cacheServiceName, cacheServiceArea := targets.Links.Cache(job.LinksId)
-		if len(instances) > 0 {
-			for _, inst := range instances {
-				this.agg.AddElement(inst, ifs.Leader, "", cacheServiceName, cacheServiceArea, ifs.PATCH)
+		patches := 0
+		if len(result.Instances) > 0 {
+			for _, inst := range result.Instances {
+				if this.patch(job, inst, cacheServiceName, cacheServiceArea) {
+					patches++
+				}
 			}
-		} else {
-			this.agg.AddElement(elem, ifs.Leader, "", cacheServiceName, cacheServiceArea, ifs.PATCH)
+		} else if this.patch(job, elem, cacheServiceName, cacheServiceArea) {
+			patches++
+		}
+		this.metrics.Patched(job, patches)
+
+		// Only a complete table tells which instances are gone
+		if this.instances != nil && err == nil && producesInstances(poll) {
+			this.deleteMissingInstances(job, result.Instances, cacheServiceName, cacheServiceArea)
 		}
 	}
 }
 
-// HandleDelete processes a delete CJob from the collector. It decodes the
-// resource keys from CJob.Result (a serialized CMap), constructs a minimal
-// proto instance with primary key fields set, and forwards it to the
-// inventory cache with ifs.DELETE action.
-func (this *ParsingService) HandleDelete(job *l8tpollaris.CJob) {
-	cmap := &l8tpollaris.CMap{}
-	if err := proto.Unmarshal(job.Result, cmap); err != nil {
-		this.resources.Logger().Error("HandleDelete unmarshal: ", err.Error())
-		return
+// patch sends the parsed object to the inventory cache, reduced to the fields that
+// changed since the last object of the same key when delta PATCHes are enabled.
+// It returns false when nothing changed and nothing was sent.
+func (this *ParsingService) patch(job *l8tpollaris.CJob, obj interface{}, cacheServiceName string, cacheServiceArea byte) bool {
+	if this.deltas != nil {
+		delta, changed := this.deltas.Delta(this.objectKey(job, obj), obj, this.primaryKeys)
+		if !changed {
+			return false
+		}
+		obj = delta
+	}
+	return this.send(obj, cacheServiceName, cacheServiceArea, ifs.PATCH)
+}
+
+// send queues an element for the inventory cache, returning false and logging it when the
+// outbox rejects it because the service is shutting down.
+func (this *ParsingService) send(elem interface{}, cacheServiceName string, cacheServiceArea byte, action ifs.Action) bool {
+	err := this.outbox.Add(elem, cacheServiceName, cacheServiceArea, action)
+	if err != nil {
+		this.resources.Logger().Error("ParsingCenter: dropping element for ", cacheServiceName, ": ", err.Error())
+		return false
+	}
+	return true
+}
+
+// objectKey identifies a parsed object by the job's pollaris and job, as each job only
+// populates its own part of the object, and the object's primary key values.
+func (this *ParsingService) objectKey(job *l8tpollaris.CJob, obj interface{}) string {
+	return job.PollarisName + "." + job.JobName + "/" + primaryKeyOf(obj, this.primaryKeys)
+}
+
+// deleteMissingInstances sends a DELETE for the instances that disappeared from the table
+// of the job's element, pollaris and job for longer than the grace period.
+func (this *ParsingService) deleteMissingInstances(job *l8tpollaris.CJob, instances []interface{},
+	cacheServiceName string, cacheServiceArea byte) {
+	gone := this.instances.Update(instanceTableKey(job), instances, this.primaryKeys)
+	for _, stub := range gone {
+		this.send(stub, cacheServiceName, cacheServiceArea, ifs.DELETE)
+		if this.deltas != nil {
+			this.deltas.Forget(this.objectKey(job, stub))
+		}
+	}
+	if len(gone) > 0 {
+		this.metrics.Deleted(job, len(gone))
+		this.resources.Logger().Info("ParsingCenter: deleted ", len(gone), " instances missing from ",
+			job.TargetId, " - ", job.PollarisName, " - ", job.JobName)
 	}
+}
+
+// instanceTableKey identifies the instance table of the job's element, pollaris and job.
+func instanceTableKey(job *l8tpollaris.CJob) string {
+	return job.LinksId + "/" + job.HostId + "/" + job.PollarisName + "." + job.JobName
+}
 
-	namespace := string(cmap.Data["namespace"])
-	name := string(cmap.Data["name"])
+// producesInstances returns true if any attribute of the poll creates instances.
+func producesInstances(poll *l8tpollaris.L8Poll) bool {
+	for _, attr := range poll.Attributes {
+		for _, rule := range attr.Rules {
+			if rule.Name == "CTableToInstances" {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	key := name
-	if namespace != "" {
-		key = namespace + "/" + name
+// HandleDelete processes a delete CJob from the collector. It builds the delete stub of
+// the job (see DeleteStub) and forwards it to the inventory cache with ifs.DELETE action.
+func (this *ParsingService) HandleDelete(job *l8tpollaris.CJob) {
+	elem, key, err := this.DeleteStub(job)
+	if err != nil {
+		this.resources.Logger().Error("HandleDelete: ", err.Error())
+		return
 	}
 
 	cacheServiceName, cacheServiceArea := targets.Links.Cache(job.LinksId)
+	this.resources.Logger().Debug("HandleDelete: linksId=", job.LinksId, " host=", job.HostId, " key=", key,
+		" -> cache=(", cacheServiceName, ",", cacheServiceArea, ")")
 
-	elem := this.createElementInstance(job)
-	// Set the Key field for the composite primary key
-	v := reflect.ValueOf(elem).Elem()
-	keyField := v.FieldByName("Key")
-	if keyField.IsValid() && keyField.CanSet() {
-		keyField.Set(reflect.ValueOf(key))
+	if !this.send(elem, cacheServiceName, cacheServiceArea, ifs.DELETE) {
+		return
+	}
+	this.metrics.Deleted(job, 1)
+	if this.deltas != nil {
+		this.deltas.Forget(this.objectKey(job, elem))
 	}
+	if this.instances != nil {
+		this.instances.Forget(instanceTableKey(job), elem, this.primaryKeys)
+	}
+}
 
-	fmt.Printf("[PARSER-FWD-DELETE] linksId=%s cluster=%s key=%s -> cache=(%s,%d)\n",
-		job.LinksId, job.HostId, key, cacheServiceName, cacheServiceArea)
+// DeleteStub decodes the resource keys of a delete CJob from CJob.Result (a serialized
+// CMap) and returns a minimal instance with the primary key fields set, along with the
+// composite key of the resource.
+//
+// The primary keys mapped by the key sources are set from the job, by default the
+// first one from the job's HostId. The other primary key fields are set
+// from the CMap entries of the same name, or from the composite key built from the
+// key columns of the originating poll (its StringToCTable key_column), joined with
+// "/" like CTableToInstances builds the Key of the instances it creates.
+func (this *ParsingService) DeleteStub(job *l8tpollaris.CJob) (interface{}, string, error) {
+	cmap := &l8tpollaris.CMap{}
+	if err := proto.Unmarshal(job.Result, cmap); err != nil {
+		return nil, "", errors.New("cannot unmarshal the delete keys: " + err.Error())
+	}
+
+	var keyColumns [][]string
+	poll, err := pollaris.Poll(job.PollarisName, job.JobName, this.resources)
+	if err == nil && poll != nil {
+		keyColumns = KeyColumnNames(poll)
+	}
+	key := DeleteKey(cmap, keyColumns)
 
-	this.agg.AddElement(elem, ifs.Leader, "", cacheServiceName, cacheServiceArea, ifs.DELETE)
+	elem, err := this.createElementInstance(job)
+	if err != nil {
+		return nil, key, err
+	}
+	unset := SetDeleteKeyFields(elem, this.primaryKeys, this.keySources, cmap, key)
+	if len(unset) > 0 {
+		return nil, key, errors.New("cannot resolve primary keys " + strings.Join(unset, ",") + " of " +
+			job.PollarisName + " - " + job.JobName + " for " + job.HostId + ", key '" + key + "'")
+	}
+	return elem, key, nil
 }
@@ -16,18 +16,21 @@ limitations under the License.
 package service
 
 import (
-	"os"
+	"errors"
+	"fmt"
 	"sync"
 
+	"github.com/saichler/l8parser/go/parser/rules"
 	"github.com/saichler/l8pollaris/go/pollaris/targets"
 	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
 	"github.com/saichler/l8types/go/ifs"
-	"github.com/saichler/l8utils/go/utils/aggregator"
+	"github.com/saichler/l8types/go/types/l8api"
 	"github.com/saichler/l8utils/go/utils/strings"
-	"google.golang.org/protobuf/encoding/protojson"
+	"github.com/saichler/l8utils/go/utils/web"
 )
 
-// JobFileLocation is the directory path where job results are persisted when persistence is enabled.
+// JobFileLocation is the default directory path where job results are persisted when persistence is enabled.
 const (
 	JobFileLocation = "./jobsPersistency/"
 )
@@ -39,10 +42,22 @@ const (
 type ParsingService struct {
 	resources   ifs.IResources
 	elem        interface{}
-	primaryKey  string
 	vnic        ifs.IVNic
-	agg         *aggregator.Aggregator
+	outbox      *Outbox
 	persistJobs bool
+	jobStore    JobStore
+	config      *Config
+	metrics     *Metrics
+	pool        *WorkerPool
+	tracker     *JobTracker
+	deltas      *DeltaTracker
+	reconciler  *Reconciler
+	instances   *InstanceTracker
+	deadLetters *DeadLetterStore
+	primaryKeys []string
+	keySources  []*KeySource
+	serviceName string
+	serviceArea byte
 	//itemsQueue    map[string]*InventoryQueue
 	//itemsQueueMtx *sync.Mutex
 	active          bool
@@ -62,18 +77,73 @@ func Activate(linksID string, serviceItem interface{}, persist bool, vnic ifs.IV
 	vnic.Resources().Services().Activate(sla, vnic)
 }
 
+// ActivateWithConfig initializes and registers the parsing service like Activate,
+// with the optional settings of config. A nil config uses the defaults.
+func ActivateWithConfig(linksID string, serviceItem interface{}, config *Config, vnic ifs.IVNic, primaryKeys ...string) {
+	if config == nil {
+		config = NewConfig()
+	}
+	parserServiceName, parserServiceArea := targets.Links.Parser(linksID)
+	vnic.Resources().Logger().Info("Activating parser service ", parserServiceName, " area ", parserServiceArea, " with ", linksID)
+	sla := ifs.NewServiceLevelAgreement(&ParsingService{}, parserServiceName, parserServiceArea, true, nil)
+	sla.SetServiceItem(serviceItem)
+	sla.SetPrimaryKeys(primaryKeys...)
+	sla.SetArgs(config.PersistJobs, config)
+	vnic.Resources().Services().Activate(sla, vnic)
+}
+
 // Activate is called when the service is activated. It initializes the service state,
 // registers required types with the registry, and sets up job persistence if enabled.
 func (this *ParsingService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IVNic) error {
 	this.vnic = vnic
-	this.agg = aggregator.NewAggregator(vnic, 5, 30)
 	this.registeredLinks = &sync.Map{}
 	this.resources = vnic.Resources()
+	this.config = configFromArgs(sla.Args())
+	sender := this.config.Sender
+	if sender == nil {
+		sender = func(serviceName string, serviceArea byte, action ifs.Action, elements []interface{}) error {
+			return vnic.Leader(serviceName, serviceArea, action, elements)
+		}
+	}
+	this.outbox = NewOutbox(sender, 0, 0)
+	this.serviceName = sla.ServiceName()
+	this.serviceArea = sla.ServiceArea()
+	this.metrics = NewMetrics()
+	this.tracker = NewJobTracker(this.config.UnchangedRefresh)
+	if this.config.DeltaPatches {
+		this.deltas = NewDeltaTracker(this.config.FullRefresh)
+	}
+	if this.config.ReconcileTables {
+		this.reconciler = NewReconciler(this.config.GhostMaxAge, this.config.NotPresentIntFields...)
+	}
+	if this.config.DeleteMissingInstances {
+		this.instances = NewInstanceTracker(this.config.DeleteGrace)
+	}
+	this.deadLetters = NewDeadLetterStore(this.config.DeadLetters)
+	this.pool = NewWorkerPool(this.config.Workers, this.config.QueueDepth, this.config.SubmitTimeout, this.processJob)
 	this.resources.Registry().Register(&l8tpollaris.CMap{})
 	this.resources.Registry().Register(&l8tpollaris.CTable{})
 	this.resources.Registry().Register(&l8tpollaris.CJob{})
 	this.elem = sla.ServiceItem()
-	this.primaryKey = sla.PrimaryKeys()[0]
+	this.primaryKeys = sla.PrimaryKeys()
+	this.keySources = this.config.KeySources
+	var err error
+	if len(this.keySources) == 0 {
+		this.keySources, err = defaultKeySources(this.primaryKeys)
+		if err != nil {
+			return this.resources.Logger().Error("Cannot activate parser service: ", err.Error())
+		}
+	}
+	err = validateKeySources(this.elem, this.keySources)
+	if err != nil {
+		return this.resources.Logger().Error("Cannot activate parser service: ", err.Error())
+	}
+	if this.config.DeviceClassifierRules != "" {
+		err = rules.LoadClassifierRules(this.config.DeviceClassifierRules)
+		if err != nil {
+			return this.resources.Logger().Error("Cannot activate parser service: ", err.Error())
+		}
+	}
 	this.persistJobs = sla.Args()[0].(bool)
 	vnic.Resources().Introspector().Decorators().AddPrimaryKeyDecorator(this.elem, sla.PrimaryKeys()...)
 	//this.itemsQueueMtx = &sync.Mutex{}
@@ -82,17 +152,38 @@ func (this *ParsingService) Activate(sla *ifs.ServiceLevelAgreement, vnic ifs.IV
 
 	this.resources.Introspector().Inspect(this.elem)
 	if this.persistJobs {
-		os.Mkdir(JobFileLocation, 0777)
+		this.jobStore = this.config.JobStore
+		if this.jobStore == nil {
+			this.jobStore = NewFileJobStore(DefaultJobStoreConfig())
+		}
 	}
 	//go this.watchItemsQueue()
 	return nil
 }
 
-// DeActivate is called when the service is deactivated. It cleans up service resources.
+// DeActivate is called when the service is deactivated. It stops accepting jobs, lets the
+// queued jobs finish parsing and flushes the pending PATCH and DELETE elements to the
+// inventory within Config.ShutdownTimeout, and logs what could not be completed.
+// The service resources are kept while jobs are still being parsed in the background.
 func (this *ParsingService) DeActivate() error {
 	//this.itemsQueueMtx.Lock()
 	//defer this.itemsQueueMtx.Unlock()
 	this.active = false
+	timeout := DefaultShutdownTimeout
+	if this.config != nil {
+		timeout = this.config.ShutdownTimeout
+	}
+	report := this.shutdown(timeout)
+	if this.resources != nil {
+		if report.Clean() {
+			this.resources.Logger().Info("Parser service ", this.serviceName, " shut down: ", report.String())
+		} else {
+			this.resources.Logger().Error("Parser service ", this.serviceName, " shut down incomplete: ", report.String())
+		}
+	}
+	if report.InProgress > 0 {
+		return nil
+	}
 	this.vnic = nil
 	this.resources = nil
 	this.elem = nil
@@ -100,27 +191,73 @@ func (this *ParsingService) DeActivate() error {
 	return nil
 }
 
-// Post handles incoming collection job results. It optionally persists jobs to disk
-// and triggers the JobComplete handler for each received job.
+// Post handles incoming collection job results. It queues each received job on the worker
+// pool, which optionally persists it to disk and runs the JobComplete handler, so the caller
+// does no I/O. Jobs of the same element are handled in the order they were received.
+// Jobs received while the service is not active are dropped.
 func (this *ParsingService) Post(pbs ifs.IElements, vnic ifs.IVNic) ifs.IElements {
+	if !this.active || this.pool == nil {
+		vnic.Resources().Logger().Error("Dropping ", len(pbs.Elements()), " jobs: ", ErrPoolClosed.Error())
+		return nil
+	}
 	for _, pb := range pbs.Elements() {
 		job := pb.(*l8tpollaris.CJob)
-		if this.persistJobs {
-			data, err := protojson.Marshal(job)
-			if err != nil {
-				vnic.Resources().Logger().Error("Failed to marshal job to JSON", "error", err)
-			} else {
-				err = os.WriteFile(jobFileName(job), data, 0777)
-				if err != nil {
-					vnic.Resources().Logger().Error("Failed to save job to file", "error", err)
-				}
+		this.metrics.JobReceived(job)
+		vnic.Resources().Logger().Debug("Received Job ", job.TargetId, " - ", job.HostId, " - ", job.PollarisName, " - ", job.JobName, " response")
+		err := this.pool.Submit(job)
+		if err != nil {
+			this.metrics.JobFailed(job)
+			vnic.Resources().Logger().Error("Dropping job ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName, ": ", err.Error())
+			if err == ErrPoolClosed {
+				return nil
 			}
 		}
-		vnic.Resources().Logger().Debug("Received Job ", job.TargetId, " - ", job.HostId, " - ", job.PollarisName, " - ", job.JobName, " response")
-		this.JobComplete(job, this.resources)
 	}
 	return nil
 }
+
+// processJob is the worker pool handler, persisting the job when enabled and parsing it.
+// A panic while parsing a job is logged instead of taking down the worker.
+func (this *ParsingService) processJob(job *l8tpollaris.CJob) {
+	resources := this.resources
+	defer func() {
+		if r := recover(); r != nil {
+			this.metrics.JobFailed(job)
+			this.deadLetters.Add(job, errors.New(fmt.Sprint("panic: ", r)))
+			if resources != nil {
+				resources.Logger().Error("Panic while parsing job ", job.TargetId, " - ", job.PollarisName, " - ", job.JobName, ": ", r)
+			}
+		}
+	}()
+	if resources == nil {
+		// the service was deactivated, there is nothing to parse the job with
+		this.metrics.JobFailed(job)
+		return
+	}
+	if this.persistJobs {
+		this.persistJob(job)
+	}
+	this.JobComplete(job, resources)
+}
+
+// persistJob saves the job to the job store, redacted when a Redactor is configured.
+func (this *ParsingService) persistJob(job *l8tpollaris.CJob) {
+	persisted := job
+	if this.config.Redactor != nil {
+		redacted, err := this.config.Redactor.Redact(job, this.resources)
+		if err != nil {
+			// Never write an unredacted job to disk
+			this.resources.Logger().Error("Failed to redact job, not persisting it: ", err.Error())
+			return
+		}
+		persisted = redacted
+	}
+	err := this.jobStore.Save(persisted)
+	if err != nil {
+		this.resources.Logger().Error("Failed to persist job: ", err.Error())
+	}
+}
+
 func (this *ParsingService) Put(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
 	return nil
 }
@@ -137,8 +274,10 @@ func (this *ParsingService) Delete(pb ifs.IElements, vnic ifs.IVNic) ifs.IElemen
 	}
 	return nil
 }
+
+// Get returns the parser metrics as a CTable, one row per pollaris, job, rule and target.
 func (this *ParsingService) Get(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
-	return nil
+	return object.New(nil, this.metrics.Table())
 }
 func (this *ParsingService) GetCopy(pb ifs.IElements, vnic ifs.IVNic) ifs.IElements {
 	return nil
@@ -149,23 +288,62 @@ func (this *ParsingService) Failed(pb ifs.IElements, vnic ifs.IVNic, msg *ifs.Me
 func (this *ParsingService) TransactionConfig() ifs.ITransactionConfig {
 	return nil
 }
+
+// WebService exposes the metrics Get to the UI.
 func (this *ParsingService) WebService() ifs.IWebService {
+	ws := web.New(this.serviceName, this.serviceArea, 0)
+	ws.AddEndpoint(&l8api.L8Query{}, ifs.GET, &l8tpollaris.CTable{})
+	return ws
+}
+
+// Metrics returns the counters and latency histograms of the jobs handled by the service.
+func (this *ParsingService) Metrics() *Metrics {
+	return this.metrics
+}
+
+// DeadLetters returns the jobs that failed to parse, from the oldest to the most
+// recent failure.
+func (this *ParsingService) DeadLetters() []*DeadLetter {
+	return this.deadLetters.List()
+}
+
+// Reparse queues the job of the dead letter of key to be parsed again, e.g. after the
+// pollaris or rule it failed on was fixed. The dead letter is dropped once the job
+// parses; a job failing again has the Attempts of its dead letter incremented.
+func (this *ParsingService) Reparse(key string) error {
+	letter := this.deadLetters.Get(key)
+	if letter == nil {
+		return errors.New("no dead letter " + key)
+	}
+	err := this.pool.Submit(letter.Job)
+	if err != nil {
+		return err
+	}
+	this.resources.Logger().Info("Reparsing job ", letter.Job.TargetId, " - ", letter.Job.PollarisName, " - ", letter.Job.JobName)
 	return nil
 }
 
-func jobFileName(job *l8tpollaris.CJob) string {
-	return strings.New(JobFileLocation, job.PollarisName, ".", job.JobName, ".", job.TargetId, ".", job.HostId).String()
+// ReparseAll queues all the dead letters to be parsed again and returns the number
+// of jobs queued.
+func (this *ParsingService) ReparseAll() int {
+	queued := 0
+	for _, letter := range this.deadLetters.List() {
+		if this.Reparse(letter.Key) == nil {
+			queued++
+		}
+	}
+	return queued
 }
 
 // LoadJob loads a persisted job from disk for replay or debugging purposes.
 // Parameters: pollarisName, jobName, deviceId, hostId to identify the job file.
+// It returns the most recent job of the default FileJobStore, falling back to the
+// flat file layout written by earlier versions.
 func LoadJob(pollarisName, jobName, deviceId, hostId string) (*l8tpollaris.CJob, error) {
-	filename := strings.New(JobFileLocation, pollarisName, ".", jobName, ".", deviceId, ".", hostId).String()
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
+	job, err := NewFileJobStore(DefaultJobStoreConfig()).Load(pollarisName, jobName, deviceId, hostId)
+	if err == nil {
+		return job, nil
 	}
-	job := &l8tpollaris.CJob{}
-	err = protojson.Unmarshal(data, job)
-	return job, err
+	filename := strings.New(JobFileLocation, pollarisName, ".", jobName, ".", deviceId, ".", hostId).String()
+	return ReadJobFile(filename)
 }
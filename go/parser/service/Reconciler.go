@@ -0,0 +1,276 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// NotPresent is the value set on string status fields of removed components.
+	NotPresent = "NOT_PRESENT"
+	// notPresentNumber is the value set on numeric status fields of removed components,
+	// the notPresent(6) value of IF-MIB ifOperStatus.
+	notPresentNumber = 6
+)
+
+// Reconciler treats a table poll as authoritative for the collections it populates.
+// It remembers the last object parsed per key, and re-adds the collection entries
+// (map entries, and list entries with an "id" field) that disappeared from the new
+// object with their status set to not present, so the removal reaches the inventory
+// through a merge PATCH. It is safe for concurrent use.
+//
+// The status fields are the fields named "status" or ending with "_status": enums are
+// set to their value whose name contains NOT_PRESENT and strings to NotPresent. Integers
+// carry no such value, so only the integer fields configured as holding IF-MIB
+// ifOperStatus values are set, to notPresent(6). An entry without any status field to
+// set, directly or in its nested components, cannot be marked and is left out.
+type Reconciler struct {
+	mtx  *sync.Mutex
+	last map[string]proto.Message
+	// ghosts holds, per key, the time each re-added entry was first marked, by entry path.
+	ghosts map[string]map[string]time.Time
+	// ghostMaxAge is how long a re-added entry is carried. Zero carries it until it shows up again.
+	ghostMaxAge time.Duration
+	// intStatusFields are the full names of the integer status fields holding ifOperStatus values.
+	intStatusFields map[protoreflect.FullName]bool
+}
+
+// NewReconciler creates an empty Reconciler carrying the re-added entries for ghostMaxAge.
+// intStatusFields are the full protobuf names of the integer status fields holding
+// ifOperStatus values, e.g. "types.Port.oper_status", set to notPresent(6).
+func NewReconciler(ghostMaxAge time.Duration, intStatusFields ...string) *Reconciler {
+	this := &Reconciler{mtx: &sync.Mutex{}, last: make(map[string]proto.Message),
+		ghosts: make(map[string]map[string]time.Time), ghostMaxAge: ghostMaxAge,
+		intStatusFields: make(map[protoreflect.FullName]bool)}
+	for _, name := range intStatusFields {
+		this.intStatusFields[protoreflect.FullName(name)] = true
+	}
+	return this
+}
+
+// reconcile holds the state of a single Reconcile call.
+type reconcile struct {
+	*Reconciler
+	now time.Time
+	// prevGhosts are the entries marked by the previous calls of the key.
+	prevGhosts map[string]time.Time
+	// ghosts are the entries marked by this call, carried to the next one.
+	ghosts map[string]time.Time
+}
+
+// Reconcile adds to obj the entries of the last object of key missing from obj, marked
+// as not present, and returns the number of entries added. Entries marked before stay
+// in the object until they show up again with their own status, or until they have
+// been carried for the ghost max age.
+// Objects that are not protobuf messages are left as is.
+func (this *Reconciler) Reconcile(key string, obj interface{}) int {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return 0
+	}
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	marked := 0
+	state := &reconcile{Reconciler: this, now: time.Now(), prevGhosts: this.ghosts[key],
+		ghosts: make(map[string]time.Time)}
+	if last, ok := this.last[key]; ok {
+		marked = state.message("", last.ProtoReflect(), msg.ProtoReflect())
+	}
+	this.last[key] = msg
+	if len(state.ghosts) > 0 {
+		this.ghosts[key] = state.ghosts
+	} else {
+		delete(this.ghosts, key)
+	}
+	return marked
+}
+
+// Forget drops the last object of key.
+func (this *Reconciler) Forget(key string) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	delete(this.last, key)
+	delete(this.ghosts, key)
+}
+
+// ghost records that the entry at path is re-added, returning false when it has been
+// carried for longer than the ghost max age and is dropped instead.
+func (this *reconcile) ghost(path string) bool {
+	marked, ok := this.prevGhosts[path]
+	if !ok {
+		marked = this.now
+	}
+	if this.ghostMaxAge > 0 && this.now.Sub(marked) >= this.ghostMaxAge {
+		return false
+	}
+	this.ghosts[path] = marked
+	return true
+}
+
+// message re-adds to curr the collection entries of prev it is missing,
+// descending into the entries and nested messages present in both.
+func (this *reconcile) message(path string, prev, curr protoreflect.Message) int {
+	marked := 0
+	prev.Range(func(fd protoreflect.FieldDescriptor, pv protoreflect.Value) bool {
+		fieldPath := path + "." + string(fd.Name())
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				marked += this.mapEntries(fieldPath, fd, pv.Map(), curr)
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				marked += this.listEntries(fieldPath, fd, pv.List(), curr)
+			}
+		case fd.Message() != nil:
+			if curr.Has(fd) {
+				marked += this.message(fieldPath, pv.Message(), curr.Get(fd).Message())
+			}
+		}
+		return true
+	})
+	return marked
+}
+
+func (this *reconcile) mapEntries(path string, fd protoreflect.FieldDescriptor, prev protoreflect.Map, curr protoreflect.Message) int {
+	marked := 0
+	prev.Range(func(k protoreflect.MapKey, pv protoreflect.Value) bool {
+		entryPath := path + "[" + k.String() + "]"
+		if curr.Has(fd) && curr.Get(fd).Map().Has(k) {
+			marked += this.message(entryPath, pv.Message(), curr.Get(fd).Map().Get(k).Message())
+			return true
+		}
+		removed := proto.Clone(pv.Message().Interface()).ProtoReflect()
+		if this.markNotPresent(removed) && this.ghost(entryPath) {
+			curr.Mutable(fd).Map().Set(k, protoreflect.ValueOfMessage(removed))
+			marked++
+		}
+		return true
+	})
+	return marked
+}
+
+func (this *reconcile) listEntries(path string, fd protoreflect.FieldDescriptor, prev protoreflect.List, curr protoreflect.Message) int {
+	idField := fd.Message().Fields().ByName("id")
+	if idField == nil || idField.IsList() || idField.IsMap() {
+		return 0
+	}
+	currIds := make(map[interface{}]protoreflect.Message)
+	if curr.Has(fd) {
+		list := curr.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			entry := list.Get(i).Message()
+			currIds[entry.Get(idField).Interface()] = entry
+		}
+	}
+
+	marked := 0
+	for i := 0; i < prev.Len(); i++ {
+		pv := prev.Get(i).Message()
+		id := pv.Get(idField).Interface()
+		entryPath := path + "[" + fmt.Sprint(id) + "]"
+		if entry, ok := currIds[id]; ok {
+			marked += this.message(entryPath, pv, entry)
+			continue
+		}
+		removed := proto.Clone(pv.Interface()).ProtoReflect()
+		if this.markNotPresent(removed) && this.ghost(entryPath) {
+			curr.Mutable(fd).List().Append(protoreflect.ValueOfMessage(removed))
+			marked++
+		}
+	}
+	return marked
+}
+
+// markNotPresent sets the status fields of msg and of its nested components to not
+// present, returning false when there is no status field to set.
+func (this *reconcile) markNotPresent(msg protoreflect.Message) bool {
+	marked := false
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsMap() {
+			if fd.MapValue().Message() != nil && msg.Has(fd) {
+				msg.Get(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					marked = this.markNotPresent(v.Message()) || marked
+					return true
+				})
+			}
+			continue
+		}
+		if fd.IsList() {
+			if fd.Message() != nil && msg.Has(fd) {
+				list := msg.Get(fd).List()
+				for j := 0; j < list.Len(); j++ {
+					marked = this.markNotPresent(list.Get(j).Message()) || marked
+				}
+			}
+			continue
+		}
+		if fd.Message() != nil {
+			if msg.Has(fd) {
+				marked = this.markNotPresent(msg.Get(fd).Message()) || marked
+			}
+			continue
+		}
+		name := string(fd.Name())
+		if name != "status" && !strings.HasSuffix(name, "_status") {
+			continue
+		}
+		if value, ok := this.notPresentValue(fd); ok {
+			msg.Set(fd, value)
+			marked = true
+		}
+	}
+	return marked
+}
+
+// notPresentValue returns the not present value of a status field.
+func (this *reconcile) notPresentValue(fd protoreflect.FieldDescriptor) (protoreflect.Value, bool) {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			if strings.Contains(string(values.Get(i).Name()), NotPresent) {
+				return protoreflect.ValueOfEnum(values.Get(i).Number()), true
+			}
+		}
+		return protoreflect.Value{}, false
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(NotPresent), true
+	}
+	if !this.intStatusFields[fd.FullName()] {
+		return protoreflect.Value{}, false
+	}
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(notPresentNumber), true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(notPresentNumber), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(notPresentNumber), true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(notPresentNumber), true
+	}
+	return protoreflect.Value{}, false
+}
@@ -0,0 +1,325 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+)
+
+// RedactedValue replaces the masked values in persisted jobs.
+const RedactedValue = "***REDACTED***"
+
+// RedactionRule describes which values of a job are masked before it is persisted.
+// PollarisName and JobName select the jobs the rule applies to; empty matches any.
+type RedactionRule struct {
+	PollarisName string
+	JobName      string
+	// All masks every string value of the matching jobs.
+	All bool
+	// Keys masks the values of these CMap keys (e.g. OIDs) and CTable column names.
+	Keys []string
+	// KeyPattern masks the values of the CMap keys and CTable column names matching
+	// this regular expression.
+	KeyPattern string
+	// JSONPaths masks the values at these dot separated paths, e.g. "data.password",
+	// inside values holding a JSON document. A dot inside a key is escaped as "\.".
+	JSONPaths []string
+	// Pattern masks the matches of this regular expression in every string value.
+	Pattern string
+}
+
+// Redactor masks sensitive values of collection jobs, such as K8s Secret listings and
+// SSH command output, before they are written to disk. Values are masked in place and
+// re-encoded, so a redacted job still decodes and replays through the parser.
+type Redactor struct {
+	rules []*redactionRule
+}
+
+type redactionRule struct {
+	*RedactionRule
+	keys       map[string]bool
+	keyPattern *regexp.Regexp
+	paths      [][]string
+	pattern    *regexp.Regexp
+}
+
+// NewRedactor creates a Redactor from the given rules, failing on an invalid pattern.
+func NewRedactor(rules ...*RedactionRule) (*Redactor, error) {
+	redactor := &Redactor{rules: make([]*redactionRule, 0, len(rules))}
+	for _, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		compiled := &redactionRule{RedactionRule: rule, keys: make(map[string]bool)}
+		for _, key := range rule.Keys {
+			compiled.keys[key] = true
+		}
+		if rule.KeyPattern != "" {
+			keyPattern, err := regexp.Compile(rule.KeyPattern)
+			if err != nil {
+				return nil, errors.New("invalid redaction key pattern " + rule.KeyPattern + ": " + err.Error())
+			}
+			compiled.keyPattern = keyPattern
+		}
+		for _, path := range rule.JSONPaths {
+			compiled.paths = append(compiled.paths, splitJSONPath(path))
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, errors.New("invalid redaction pattern " + rule.Pattern + ": " + err.Error())
+			}
+			compiled.pattern = pattern
+		}
+		redactor.rules = append(redactor.rules, compiled)
+	}
+	return redactor, nil
+}
+
+// DefaultRedactionRules returns the rules masking the sensitive data of the collected jobs:
+// the values held under credential named CMap keys and CTable columns, the last applied
+// configuration annotation and the container environment values of the kubectl details
+// (which may carry the manifests' secrets), and password/secret/token/key assignments in
+// command output. The K8s Secret and ConfigMap listings only collect names and counts.
+func DefaultRedactionRules() []*RedactionRule {
+	credentials := `(?i)(password|passwd|secret|token|community|credential|api[_-]?key|private[_-]?key)`
+	return []*RedactionRule{
+		{KeyPattern: credentials},
+		{PollarisName: "kubernetes", JSONPaths: []string{
+			`metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration`,
+			"spec.containers.env.value",
+			"spec.initContainers.env.value",
+			"spec.template.spec.containers.env.value",
+			"spec.template.spec.initContainers.env.value",
+		}},
+		{Pattern: `(?i)((password|passwd|secret|token|community|api[_-]?key)\s*[:=]\s*)\S+`},
+	}
+}
+
+// Redact returns a copy of the job with the sensitive values masked. The job itself is
+// never modified, and is returned as is when no rule applies to it.
+func (this *Redactor) Redact(job *l8tpollaris.CJob, resources ifs.IResources) (*l8tpollaris.CJob, error) {
+	rules := this.rulesOf(job)
+	if len(rules) == 0 || len(job.Result) == 0 {
+		return job, nil
+	}
+
+	dec := object.NewDecode(job.Result, 0, resources.Registry())
+	result, err := dec.Get()
+	if err != nil {
+		return nil, errors.New("failed to decode job result for redaction: " + err.Error())
+	}
+
+	switch value := result.(type) {
+	case *l8tpollaris.CMap:
+		err = redactMap(value, rules, resources)
+	case *l8tpollaris.CTable:
+		err = redactTable(value, rules, resources)
+	case string:
+		result = redactString("", value, rules)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := proto.Clone(job).(*l8tpollaris.CJob)
+	enc := object.NewEncode()
+	enc.Add(result)
+	redacted.Result = enc.Data()
+	if redacted.Error != "" {
+		redacted.Error = redactString("", redacted.Error, rules)
+	}
+	return redacted, nil
+}
+
+// rulesOf returns the rules applying to the job.
+func (this *Redactor) rulesOf(job *l8tpollaris.CJob) []*redactionRule {
+	result := make([]*redactionRule, 0)
+	for _, rule := range this.rules {
+		if rule.PollarisName != "" && rule.PollarisName != job.PollarisName {
+			continue
+		}
+		if rule.JobName != "" && rule.JobName != job.JobName {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// redactMap masks the values of a CMap. The result is decoded into a new map, as the
+// decoded CMap may share its value buffers with the original job.
+func redactMap(cmap *l8tpollaris.CMap, rules []*redactionRule, resources ifs.IResources) error {
+	data := make(map[string][]byte, len(cmap.Data))
+	for key, raw := range cmap.Data {
+		masked, err := redactValue(key, raw, rules, resources)
+		if err != nil {
+			return err
+		}
+		data[key] = masked
+	}
+	cmap.Data = data
+	return nil
+}
+
+// redactTable masks the values of a CTable, matching Keys against the column names.
+func redactTable(table *l8tpollaris.CTable, rules []*redactionRule, resources ifs.IResources) error {
+	for _, row := range table.Rows {
+		if row == nil {
+			continue
+		}
+		data := make(map[int32][]byte, len(row.Data))
+		for col, raw := range row.Data {
+			masked, err := redactValue(table.Columns[col], raw, rules, resources)
+			if err != nil {
+				return err
+			}
+			data[col] = masked
+		}
+		row.Data = data
+	}
+	return nil
+}
+
+// redactValue masks a single encoded value. Only string values are masked, so numeric
+// values keep their type and still parse on replay.
+func redactValue(key string, raw []byte, rules []*redactionRule, resources ifs.IResources) ([]byte, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	dec := object.NewDecode(raw, 0, resources.Registry())
+	value, err := dec.Get()
+	if err != nil {
+		return nil, errors.New("failed to decode value of " + key + " for redaction: " + err.Error())
+	}
+	str, ok := value.(string)
+	if !ok {
+		return raw, nil
+	}
+	masked := redactString(key, str, rules)
+	if masked == str {
+		return raw, nil
+	}
+	enc := object.NewEncode()
+	enc.Add(masked)
+	return enc.Data(), nil
+}
+
+// redactString applies the rules to a string value held under key.
+func redactString(key, value string, rules []*redactionRule) string {
+	for _, rule := range rules {
+		if rule.All || rule.keys[key] || (key != "" && rule.keyPattern != nil && rule.keyPattern.MatchString(key)) {
+			return RedactedValue
+		}
+	}
+	for _, rule := range rules {
+		if len(rule.paths) > 0 {
+			value = redactJSON(value, rule.paths)
+		}
+		if rule.pattern != nil {
+			value = redactPattern(value, rule.pattern)
+		}
+	}
+	return value
+}
+
+// redactJSON masks the given paths of a value holding a JSON document, in every element
+// of the arrays along the path. Values that are not JSON are returned as is.
+func redactJSON(value string, paths [][]string) string {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value
+	}
+	var doc interface{}
+	if json.Unmarshal([]byte(trimmed), &doc) != nil {
+		return value
+	}
+	changed := false
+	for _, path := range paths {
+		if maskPath(doc, path) {
+			changed = true
+		}
+	}
+	if !changed {
+		return value
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return value
+	}
+	return string(data)
+}
+
+// splitJSONPath splits a dot separated path, keeping the escaped dots ("\.") in the keys.
+func splitJSONPath(path string) []string {
+	result := make([]string, 0)
+	key := strings.Builder{}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) && path[i+1] == '.' {
+			key.WriteByte('.')
+			i++
+			continue
+		}
+		if path[i] == '.' {
+			result = append(result, key.String())
+			key.Reset()
+			continue
+		}
+		key.WriteByte(path[i])
+	}
+	return append(result, key.String())
+}
+
+func maskPath(node interface{}, path []string) bool {
+	switch v := node.(type) {
+	case []interface{}:
+		changed := false
+		for _, elem := range v {
+			if maskPath(elem, path) {
+				changed = true
+			}
+		}
+		return changed
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return false
+		}
+		if len(path) == 1 {
+			v[path[0]] = RedactedValue
+			return true
+		}
+		return maskPath(child, path[1:])
+	}
+	return false
+}
+
+// redactPattern masks the matches of pattern. When the pattern has capture groups, the
+// first group is kept as a prefix, e.g. "password: " in "password: secret".
+func redactPattern(value string, pattern *regexp.Regexp) string {
+	if pattern.NumSubexp() == 0 {
+		return pattern.ReplaceAllString(value, RedactedValue)
+	}
+	return pattern.ReplaceAllString(value, "${1}"+RedactedValue)
+}
@@ -0,0 +1,116 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultShutdownTimeout is the default Config.ShutdownTimeout.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownReport tells what DeActivate could not complete before its timeout.
+type ShutdownReport struct {
+	// Abandoned is the number of queued jobs that were not parsed.
+	Abandoned int
+	// InProgress is the number of jobs still being parsed at the timeout. Their elements
+	// may not reach the inventory.
+	InProgress int
+	// Flushed is false if the elements pending in the outbox may not have been sent.
+	Flushed bool
+	// Dropped is the number of elements not sent to the inventory, per service and action,
+	// e.g. "NCache/0 PATCH": batches that failed to send and elements still pending at
+	// the timeout.
+	Dropped map[string]int
+	// DeadLetters is the number of failed jobs that were not re-parsed.
+	DeadLetters int
+	Duration    time.Duration
+}
+
+// Clean returns true if every job was parsed and every element was sent.
+func (this *ShutdownReport) Clean() bool {
+	return this.Abandoned == 0 && this.InProgress == 0 && this.Flushed && len(this.Dropped) == 0
+}
+
+func (this *ShutdownReport) String() string {
+	dropped := make([]string, 0, len(this.Dropped))
+	for key, count := range this.Dropped {
+		dropped = append(dropped, key+"="+strconv.Itoa(count))
+	}
+	sort.Strings(dropped)
+	return "abandoned jobs=" + strconv.Itoa(this.Abandoned) +
+		" in progress jobs=" + strconv.Itoa(this.InProgress) +
+		" flushed=" + strconv.FormatBool(this.Flushed) +
+		" dropped elements=[" + strings.Join(dropped, ", ") + "]" +
+		" dead letters=" + strconv.Itoa(this.DeadLetters) +
+		" duration=" + this.Duration.String()
+}
+
+// shutdown stops accepting jobs, waits for the queued jobs to be parsed and flushes the
+// elements pending in the outbox, all within timeout; zero waits without a limit.
+func (this *ParsingService) shutdown(timeout time.Duration) *ShutdownReport {
+	start := time.Now()
+	report := &ShutdownReport{}
+	if this.pool != nil {
+		report.Abandoned, report.InProgress = this.pool.Shutdown(timeout)
+	}
+	if this.deadLetters != nil {
+		report.DeadLetters = this.deadLetters.Len()
+	}
+
+	remaining := time.Duration(0)
+	if timeout > 0 {
+		remaining = timeout - time.Since(start)
+	}
+	if timeout <= 0 || remaining > 0 {
+		report.Flushed = this.flush(remaining)
+	}
+	if this.outbox != nil {
+		// Out of time, the elements still pending are not sent
+		this.outbox.Close()
+		this.outbox.Discard()
+		report.Dropped = this.outbox.Dropped()
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// flush closes the outbox and sends its pending elements, waiting up to timeout; zero
+// waits until they are sent. It returns false if sending failed or timed out.
+func (this *ParsingService) flush(timeout time.Duration) bool {
+	if this.outbox == nil {
+		return true
+	}
+	this.outbox.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- this.outbox.Flush()
+	}()
+	if timeout <= 0 {
+		return <-done == nil
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err == nil
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,325 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8reflect/go/reflect/properties"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// ValidationSeverity classifies a validation issue.
+type ValidationSeverity int
+
+const (
+	// SeverityError marks an issue that will make the attribute fail at parse time.
+	SeverityError ValidationSeverity = iota
+	// SeverityWarning marks a suspicious configuration that will not fail parsing.
+	SeverityWarning
+)
+
+func (this ValidationSeverity) String() string {
+	if this == SeverityWarning {
+		return "WARNING"
+	}
+	return "ERROR"
+}
+
+// ValidationIssue describes a single problem found in a pollaris configuration.
+type ValidationIssue struct {
+	Severity     ValidationSeverity
+	PollarisName string
+	JobName      string
+	Model        string
+	PropertyId   string
+	Rule         string
+	Param        string
+	Message      string
+}
+
+func (this *ValidationIssue) String() string {
+	buff := strings.Builder{}
+	buff.WriteString(this.Severity.String())
+	buff.WriteString(" ")
+	buff.WriteString(this.PollarisName)
+	buff.WriteString(":")
+	buff.WriteString(this.JobName)
+	if this.Model != "" {
+		buff.WriteString(" model=")
+		buff.WriteString(this.Model)
+	}
+	if this.PropertyId != "" {
+		buff.WriteString(" propertyId=")
+		buff.WriteString(this.PropertyId)
+	}
+	if this.Rule != "" {
+		buff.WriteString(" rule=")
+		buff.WriteString(this.Rule)
+	}
+	if this.Param != "" {
+		buff.WriteString(" param=")
+		buff.WriteString(this.Param)
+	}
+	buff.WriteString(" - ")
+	buff.WriteString(this.Message)
+	return buff.String()
+}
+
+// ValidationReport is the full outcome of validating one or more pollaris configurations.
+type ValidationReport struct {
+	Issues        []*ValidationIssue
+	PollarisCount int
+	PollCount     int
+	RuleCount     int
+}
+
+// Valid returns true if the report has no error-level issues.
+func (this *ValidationReport) Valid() bool {
+	return len(this.Errors()) == 0
+}
+
+// Errors returns the error-level issues of the report.
+func (this *ValidationReport) Errors() []*ValidationIssue {
+	result := make([]*ValidationIssue, 0)
+	for _, issue := range this.Issues {
+		if issue.Severity == SeverityError {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+// String returns a human readable, one issue per line, summary of the report.
+func (this *ValidationReport) String() string {
+	buff := strings.Builder{}
+	buff.WriteString("Validated ")
+	buff.WriteString(strconv.Itoa(this.PollarisCount))
+	buff.WriteString(" pollaris, ")
+	buff.WriteString(strconv.Itoa(this.PollCount))
+	buff.WriteString(" polls, ")
+	buff.WriteString(strconv.Itoa(this.RuleCount))
+	buff.WriteString(" rules, ")
+	buff.WriteString(strconv.Itoa(len(this.Issues)))
+	buff.WriteString(" issues")
+	for _, issue := range this.Issues {
+		buff.WriteString("\n  ")
+		buff.WriteString(issue.String())
+	}
+	return buff.String()
+}
+
+// pollarisValidator carries the state of a single validation run.
+type pollarisValidator struct {
+	resources ifs.IResources
+	models    map[string]bool
+	report    *ValidationReport
+	// resolved caches PropertyId resolution results, keyed by PropertyId.
+	resolved map[string]error
+}
+
+// ValidatePollaris walks every poll, attribute and rule of a pollaris and verifies that
+// the rule names exist in the Parser registry, that required parameters are present, that
+// parameter values parse according to the rule's ParamSchema (or, for rules without a
+// schema, that every name returned by ParamNames is present), and that each PropertyId
+// resolves via properties.PropertyOf. When models are given only their PropertyIds are checked,
+// otherwise every model referenced by the attributes is checked. The model types must have
+// been registered with the resources' introspector for PropertyIds to resolve.
+func ValidatePollaris(p *l8tpollaris.L8Pollaris, resources ifs.IResources, models ...string) *ValidationReport {
+	v := newPollarisValidator(resources, models)
+	v.validatePollaris(p)
+	return v.report
+}
+
+// ValidateAllPollaris validates every pollaris returned by boot.GetAllPolarisModels,
+// including the ones registered by external projects.
+func ValidateAllPollaris(resources ifs.IResources, models ...string) *ValidationReport {
+	v := newPollarisValidator(resources, models)
+	for _, p := range boot.GetAllPolarisModels() {
+		v.validatePollaris(p)
+	}
+	return v.report
+}
+
+// RegisterPollaris validates an external pollaris and, if it has no error-level issues,
+// registers it with boot.RegisterPollaris. Warnings are logged but do not block registration.
+func RegisterPollaris(p *l8tpollaris.L8Pollaris, resources ifs.IResources, models ...string) error {
+	report := ValidatePollaris(p, resources, models...)
+	if !report.Valid() {
+		return errors.New("invalid pollaris " + p.Name + ": " + report.String())
+	}
+	if len(report.Issues) > 0 {
+		resources.Logger().Warning(report.String())
+	}
+	boot.RegisterPollaris(p)
+	return nil
+}
+
+func newPollarisValidator(resources ifs.IResources, models []string) *pollarisValidator {
+	v := &pollarisValidator{resources: resources, report: &ValidationReport{}}
+	v.report.Issues = make([]*ValidationIssue, 0)
+	v.resolved = make(map[string]error)
+	if len(models) > 0 {
+		v.models = make(map[string]bool)
+		for _, model := range models {
+			v.models[model] = true
+		}
+	}
+	return v
+}
+
+func (this *pollarisValidator) validatePollaris(p *l8tpollaris.L8Pollaris) {
+	this.report.PollarisCount++
+	// Iterate polls in name order so reports are stable between runs
+	names := make([]string, 0, len(p.Polling))
+	for name := range p.Polling {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		poll := p.Polling[name]
+		if poll == nil {
+			continue
+		}
+		this.report.PollCount++
+		for _, attr := range poll.Attributes {
+			this.validateAttribute(p.Name, poll, attr)
+		}
+	}
+}
+
+func (this *pollarisValidator) validateAttribute(pollarisName string, poll *l8tpollaris.L8Poll, attr *l8tpollaris.L8PAttribute) {
+	propertyIds := sortedPropertyIds(attr)
+	if len(attr.Rules) == 0 {
+		this.add(&ValidationIssue{Severity: SeverityWarning, PollarisName: pollarisName, JobName: poll.Name,
+			PropertyId: strings.Join(propertyIds, ","), Message: "attribute has no rules"})
+	}
+
+	for _, rData := range attr.Rules {
+		this.report.RuleCount++
+		this.validateRule(pollarisName, poll, rData, strings.Join(propertyIds, ","))
+	}
+
+	for _, model := range sortedModels(attr) {
+		if this.models != nil && !this.models[model] {
+			continue
+		}
+		propertyId := attr.PropertyId[model]
+		err := this.resolve(propertyId)
+		if err != nil {
+			this.add(&ValidationIssue{Severity: SeverityError, PollarisName: pollarisName, JobName: poll.Name,
+				Model: model, PropertyId: propertyId, Message: "PropertyId does not resolve: " + err.Error()})
+		}
+	}
+}
+
+func (this *pollarisValidator) validateRule(pollarisName string, poll *l8tpollaris.L8Poll, rData *l8tpollaris.L8PRule, propertyId string) {
+	ruleImpl, ok := Parser.rules[rData.Name]
+	if !ok {
+		this.add(&ValidationIssue{Severity: SeverityError, PollarisName: pollarisName, JobName: poll.Name,
+			PropertyId: propertyId, Rule: rData.Name, Message: "rule is not registered in the Parser"})
+		return
+	}
+
+	schemaRule, ok := ruleImpl.(rules.SchemaRule)
+	if !ok {
+		// Without a schema only the presence of the required parameters can be checked
+		for _, paramName := range ruleImpl.ParamNames() {
+			if paramName == "" {
+				continue
+			}
+			if param, ok := rData.Params[paramName]; !ok || param == nil {
+				this.add(&ValidationIssue{Severity: SeverityError, PollarisName: pollarisName, JobName: poll.Name,
+					PropertyId: propertyId, Rule: rData.Name, Param: paramName, Message: "required parameter is missing"})
+			}
+		}
+		return
+	}
+
+	schema := schemaRule.ParamSchema()
+	specs := make(map[string]*rules.ParamSpec)
+	for _, spec := range schema {
+		specs[spec.Name] = spec
+		param, ok := rData.Params[spec.Name]
+		if !ok || param == nil {
+			if spec.Required && spec.Default == "" {
+				this.add(&ValidationIssue{Severity: SeverityError, PollarisName: pollarisName, JobName: poll.Name,
+					PropertyId: propertyId, Rule: rData.Name, Param: spec.Name, Message: "required parameter is missing"})
+			}
+			continue
+		}
+		_, err := rules.ParseParamValue(spec, param.Value)
+		if err != nil {
+			this.add(&ValidationIssue{Severity: SeverityError, PollarisName: pollarisName, JobName: poll.Name,
+				PropertyId: propertyId, Rule: rData.Name, Param: spec.Name, Message: err.Error()})
+		}
+	}
+
+	for _, paramName := range sortedParamNames(rData.Params) {
+		if _, ok := specs[paramName]; ok || paramName == rules.Required {
+			continue
+		}
+		this.add(&ValidationIssue{Severity: SeverityWarning, PollarisName: pollarisName, JobName: poll.Name,
+			PropertyId: propertyId, Rule: rData.Name, Param: paramName, Message: "parameter is not used by the rule"})
+	}
+}
+
+func sortedParamNames(params map[string]*l8tpollaris.L8PParameter) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve verifies that the PropertyId, after index/key injection, resolves to a property.
+func (this *pollarisValidator) resolve(propertyId string) error {
+	if err, ok := this.resolved[propertyId]; ok {
+		return err
+	}
+	_, err := properties.PropertyOf(rules.InjectIndexOrKey(propertyId, nil), this.resources)
+	this.resolved[propertyId] = err
+	return err
+}
+
+func (this *pollarisValidator) add(issue *ValidationIssue) {
+	this.report.Issues = append(this.report.Issues, issue)
+}
+
+func sortedModels(attr *l8tpollaris.L8PAttribute) []string {
+	models := make([]string, 0, len(attr.PropertyId))
+	for model := range attr.PropertyId {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+func sortedPropertyIds(attr *l8tpollaris.L8PAttribute) []string {
+	models := sortedModels(attr)
+	propertyIds := make([]string, 0, len(models))
+	for _, model := range models {
+		propertyIds = append(propertyIds, attr.PropertyId[model])
+	}
+	return propertyIds
+}
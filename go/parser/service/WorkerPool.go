@@ -0,0 +1,215 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"hash/fnv"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// DefaultQueueDepth is the per worker queue depth used when none is configured.
+const DefaultQueueDepth = 100
+
+// ErrQueueFull is returned by WorkerPool.Submit when the job could not be queued
+// within the submit timeout.
+var ErrQueueFull = errors.New("parser queue is full")
+
+// ErrPoolClosed is returned by WorkerPool.Submit once the pool was closed.
+var ErrPoolClosed = errors.New("parser worker pool is closed")
+
+// WorkerPool parses jobs concurrently while keeping the jobs of the same element, i.e.
+// the same LinksId and HostId, in order: each such key is always hashed to the same
+// worker, and every worker handles its queue serially.
+type WorkerPool struct {
+	queues        []chan *l8tpollaris.CJob
+	handler       func(*l8tpollaris.CJob)
+	submitTimeout time.Duration
+	dropped       int64
+	busy          int64
+	abandoned     int64
+	aborted       int32
+	mtx           *sync.RWMutex
+	closed        bool
+	// closing is closed when the shutdown starts, releasing the blocked senders.
+	closing     chan struct{}
+	closingOnce *sync.Once
+	// senders are the Submit calls that may still send on a queue.
+	senders *sync.WaitGroup
+	done    *sync.WaitGroup
+}
+
+// NewWorkerPool starts workers goroutines, each with a queue of queueDepth jobs, calling
+// handler for every submitted job. Zero workers uses the number of CPUs and zero depth
+// uses DefaultQueueDepth. A zero submitTimeout blocks Submit until the job is queued.
+func NewWorkerPool(workers, queueDepth int, submitTimeout time.Duration, handler func(*l8tpollaris.CJob)) *WorkerPool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if queueDepth <= 0 {
+		queueDepth = DefaultQueueDepth
+	}
+	pool := &WorkerPool{handler: handler, submitTimeout: submitTimeout, mtx: &sync.RWMutex{},
+		closing: make(chan struct{}), closingOnce: &sync.Once{}, senders: &sync.WaitGroup{}, done: &sync.WaitGroup{}}
+	pool.queues = make([]chan *l8tpollaris.CJob, workers)
+	for i := range pool.queues {
+		pool.queues[i] = make(chan *l8tpollaris.CJob, queueDepth)
+		pool.done.Add(1)
+		go pool.work(pool.queues[i])
+	}
+	return pool
+}
+
+// Submit queues the job on the worker of its LinksId and HostId. When that queue is full
+// it blocks, applying backpressure to the sender, for up to the submit timeout; a job that
+// could not be queued in time is dropped and ErrQueueFull is returned. A shutdown releases
+// the blocked Submit calls with ErrPoolClosed.
+func (this *WorkerPool) Submit(job *l8tpollaris.CJob) error {
+	this.mtx.RLock()
+	if this.closed {
+		this.mtx.RUnlock()
+		return ErrPoolClosed
+	}
+	// The queues are not closed before the registered senders are done
+	this.senders.Add(1)
+	this.mtx.RUnlock()
+	defer this.senders.Done()
+
+	queue := this.queues[this.worker(job)]
+	select {
+	case queue <- job:
+		return nil
+	case <-this.closing:
+		return ErrPoolClosed
+	default:
+	}
+	var expired <-chan time.Time
+	if this.submitTimeout > 0 {
+		timer := time.NewTimer(this.submitTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case queue <- job:
+		return nil
+	case <-this.closing:
+		return ErrPoolClosed
+	case <-expired:
+		atomic.AddInt64(&this.dropped, 1)
+		return ErrQueueFull
+	}
+}
+
+// Workers returns the number of workers.
+func (this *WorkerPool) Workers() int {
+	return len(this.queues)
+}
+
+// Pending returns the number of queued jobs not yet picked up by a worker.
+func (this *WorkerPool) Pending() int {
+	pending := 0
+	for _, queue := range this.queues {
+		pending += len(queue)
+	}
+	return pending
+}
+
+// Dropped returns the number of jobs dropped because their queue stayed full.
+func (this *WorkerPool) Dropped() int64 {
+	return atomic.LoadInt64(&this.dropped)
+}
+
+// Close stops accepting jobs and waits for the queued jobs to be handled.
+func (this *WorkerPool) Close() {
+	this.Shutdown(0)
+}
+
+// Shutdown stops accepting jobs and waits up to timeout for the queued jobs to be
+// handled; zero waits until they all are. At the timeout the jobs still queued are
+// abandoned and the jobs being handled are left to finish in the background. It returns
+// the number of abandoned jobs and of jobs still being handled. The Submit calls blocked
+// on a full queue are released first, so they do not delay the shutdown.
+func (this *WorkerPool) Shutdown(timeout time.Duration) (int, int) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	this.closingOnce.Do(func() {
+		close(this.closing)
+	})
+	this.mtx.Lock()
+	first := !this.closed
+	this.closed = true
+	this.mtx.Unlock()
+	if first {
+		this.senders.Wait()
+		for _, queue := range this.queues {
+			close(queue)
+		}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		this.done.Wait()
+		close(finished)
+	}()
+	if timeout <= 0 {
+		<-finished
+		return 0, 0
+	}
+	select {
+	case <-finished:
+		return 0, 0
+	case <-expired:
+	}
+
+	atomic.StoreInt32(&this.aborted, 1)
+	for _, queue := range this.queues {
+		for range queue {
+			atomic.AddInt64(&this.abandoned, 1)
+		}
+	}
+	return int(atomic.LoadInt64(&this.abandoned)), int(atomic.LoadInt64(&this.busy))
+}
+
+func (this *WorkerPool) work(queue chan *l8tpollaris.CJob) {
+	defer this.done.Done()
+	for job := range queue {
+		if atomic.LoadInt32(&this.aborted) == 1 {
+			atomic.AddInt64(&this.abandoned, 1)
+			continue
+		}
+		atomic.AddInt64(&this.busy, 1)
+		this.handler(job)
+		atomic.AddInt64(&this.busy, -1)
+	}
+}
+
+// worker returns the index of the worker handling the job's element.
+func (this *WorkerPool) worker(job *l8tpollaris.CJob) int {
+	h := fnv.New32a()
+	h.Write([]byte(job.LinksId))
+	h.Write([]byte{0})
+	h.Write([]byte(job.HostId))
+	return int(h.Sum32() % uint32(len(this.queues)))
+}
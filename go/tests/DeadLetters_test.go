@@ -0,0 +1,56 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestDeadLetterStore verifies that a repeated failure of the same job increments its
+// attempts, that the oldest dead letter is dropped when the store is full and that a
+// dead letter is removed once a job of its key parses.
+func TestDeadLetterStore(t *testing.T) {
+	store := parsing.NewDeadLetterStore(2)
+	job := func(host string, ended int64) *l8tpollaris.CJob {
+		return &l8tpollaris.CJob{PollarisName: "mib2", JobName: "ifTable", HostId: host, TargetId: host, Ended: ended}
+	}
+
+	store.Add(job("10.20.30.1", 100), errors.New("bad table"))
+	store.Add(job("10.20.30.1", 100), errors.New("bad table"))
+	letter := store.Get(parsing.DeadLetterKey(job("10.20.30.1", 100)))
+	if letter == nil || letter.Attempts != 2 || letter.Error != "bad table" {
+		t.Fatal("expected one dead letter with 2 attempts, got ", letter)
+	}
+
+	store.Add(job("10.20.30.2", 100), errors.New("bad table"))
+	store.Add(job("10.20.30.3", 100), errors.New("bad table"))
+	if store.Len() != 2 || store.Dropped() != 1 {
+		t.Fatal("expected 2 dead letters and 1 dropped, got ", store.Len(), " and ", store.Dropped())
+	}
+	list := store.List()
+	if list[0].Job.HostId != "10.20.30.2" || list[1].Job.HostId != "10.20.30.3" {
+		t.Fatal("expected the oldest dead letter to be dropped")
+	}
+
+	store.Remove(job("10.20.30.2", 200))
+	if store.Len() != 1 || store.Get(parsing.DeadLetterKey(job("10.20.30.2", 100))) != nil {
+		t.Fatal("expected the dead letter to be removed")
+	}
+}
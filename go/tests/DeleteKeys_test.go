@@ -0,0 +1,183 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+)
+
+// deleteResource is a K8s like resource keyed by its cluster and namespace/name key.
+type deleteResource struct {
+	ClusterName string
+	Namespace   string
+	Name        string
+	Key         string
+	Uid         int64
+}
+
+func deleteCMap(values map[string]string) *l8tpollaris.CMap {
+	cmap := &l8tpollaris.CMap{Data: make(map[string][]byte)}
+	for key, value := range values {
+		cmap.Data[key] = []byte(value)
+	}
+	return cmap
+}
+
+func bootPoll(t *testing.T, pollarisName, pollName string) *l8tpollaris.L8Poll {
+	for _, p := range boot.GetAllPolarisModels() {
+		if p.Name == pollarisName && p.Polling[pollName] != nil {
+			return p.Polling[pollName]
+		}
+	}
+	t.Fatal("no boot poll ", pollarisName, ":", pollName)
+	return nil
+}
+
+// TestKeyColumnNames verifies the key columns resolved from the column names and fields
+// of the client-go polls, and that kubectl polls without column names resolve none.
+func TestKeyColumnNames(t *testing.T) {
+	names := parsing.KeyColumnNames(bootPoll(t, "K8sPod", "pods"))
+	if !reflect.DeepEqual(names, [][]string{{"NAMESPACE", "namespace"}, {"NAME", "name"}}) {
+		t.Fatal("unexpected pod key columns ", names)
+	}
+	names = parsing.KeyColumnNames(bootPoll(t, "K8sNs", "namespaces"))
+	if !reflect.DeepEqual(names, [][]string{{"NAME", "name"}}) {
+		t.Fatal("unexpected namespace key columns ", names)
+	}
+	if names = parsing.KeyColumnNames(bootPoll(t, "kubernetes", "pods")); names != nil {
+		t.Fatal("expected no key columns for a kubectl poll, got ", names)
+	}
+}
+
+// TestDeleteKey verifies the composite keys built from the key columns, ignoring case
+// and underscores, the namespace/name fallback and the cluster scoped resources.
+func TestDeleteKey(t *testing.T) {
+	podColumns := [][]string{{"NAMESPACE", "namespace"}, {"NAME", "name"}}
+	cases := []struct {
+		name    string
+		cmap    map[string]string
+		columns [][]string
+		key     string
+	}{
+		{"Columns", map[string]string{"NAMESPACE": "prod", "NAME": "db-0"}, podColumns, "prod/db-0"},
+		{"Fields", map[string]string{"namespace": "prod", "name": "db-0"}, podColumns, "prod/db-0"},
+		{"CaseAndUnderscores", map[string]string{"Name_Space": "prod", "name": "db-0"}, podColumns, "prod/db-0"},
+		{"Fallback", map[string]string{"namespace": "prod", "name": "db-0", "uid": "42"}, nil, "prod/db-0"},
+		{"FallbackClusterScoped", map[string]string{"name": "prod"}, nil, "prod"},
+		{"ClusterScoped", map[string]string{"NAME": "prod"}, [][]string{{"NAME", "name"}}, "prod"},
+		{"Missing", map[string]string{"uid": "42"}, podColumns, ""},
+	}
+	for _, c := range cases {
+		if key := parsing.DeleteKey(deleteCMap(c.cmap), c.columns); key != c.key {
+			t.Fatal(c.name, ": expected key '", c.key, "' got '", key, "'")
+		}
+	}
+}
+
+// TestSetDeleteKeyFields verifies the primary key fields set from the delete CMap and from
+// the composite key, and that a composite key is not assigned when several fields are left.
+func TestSetDeleteKeyFields(t *testing.T) {
+	sources := []*parsing.KeySource{{Field: "ClusterName", Kind: parsing.KeyFromHostId}}
+
+	elem := &deleteResource{ClusterName: "lab"}
+	unset := parsing.SetDeleteKeyFields(elem, []string{"ClusterName", "Key"}, sources, deleteCMap(nil), "prod/db-0")
+	if len(unset) != 0 || elem.Key != "prod/db-0" || elem.ClusterName != "lab" {
+		t.Fatal("expected the composite key in Key, got ", elem, " unset ", unset)
+	}
+
+	elem = &deleteResource{ClusterName: "lab"}
+	unset = parsing.SetDeleteKeyFields(elem, []string{"ClusterName", "Namespace", "Name", "Uid"}, sources,
+		deleteCMap(map[string]string{"namespace": "prod", "name": "db-0", "uid": "42"}), "prod/db-0")
+	if len(unset) != 0 || elem.Namespace != "prod" || elem.Name != "db-0" || elem.Uid != 42 {
+		t.Fatal("expected the fields from the CMap entries, got ", elem, " unset ", unset)
+	}
+
+	elem = &deleteResource{ClusterName: "lab"}
+	unset = parsing.SetDeleteKeyFields(elem, []string{"ClusterName", "Key", "Name"}, sources,
+		deleteCMap(map[string]string{"namespace": "prod"}), "prod")
+	if !reflect.DeepEqual(unset, []string{"Key", "Name"}) || elem.Key != "" || elem.Name != "" {
+		t.Fatal("expected an ambiguous composite key not to be assigned, got ", elem, " unset ", unset)
+	}
+
+	elem = &deleteResource{ClusterName: "lab"}
+	unset = parsing.SetDeleteKeyFields(elem, []string{"ClusterName", "Uid", "Missing"}, sources, deleteCMap(nil), "prod")
+	if !reflect.DeepEqual(unset, []string{"Missing", "Uid"}) || elem.Uid != 0 {
+		t.Fatal("expected the missing and numeric fields to be reported, got ", unset)
+	}
+}
+
+// TestHandleDelete verifies the delete stubs built from kubectl and client-go delete jobs,
+// with and without a namespace, and that HandleDelete sends them and counts them.
+func TestHandleDelete(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	activateBootPollaris(vnic)
+	svc := &parsing.ParsingService{}
+	sla := ifs.NewServiceLevelAgreement(svc, "DeleteTest", 0, true, nil)
+	sla.SetServiceItem(&deleteResource{})
+	sla.SetPrimaryKeys("ClusterName", "Key")
+	sla.SetArgs(false)
+	if err := svc.Activate(sla, vnic); err != nil {
+		t.Fatal(err)
+	}
+
+	job := func(pollarisName, jobName string, values map[string]string) *l8tpollaris.CJob {
+		data, err := proto.Marshal(deleteCMap(values))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &l8tpollaris.CJob{PollarisName: pollarisName, JobName: jobName, HostId: "lab", TargetId: "lab",
+			Result: data}
+	}
+	cases := []struct {
+		pollaris string
+		job      string
+		cmap     map[string]string
+		key      string
+	}{
+		{"kubernetes", "pods", map[string]string{"namespace": "prod", "name": "db-0"}, "prod/db-0"},
+		{"kubernetes", "namespaces", map[string]string{"name": "prod"}, "prod"},
+		{"K8sPod", "pods", map[string]string{"NAMESPACE": "prod", "NAME": "db-0"}, "prod/db-0"},
+		{"K8sNs", "namespaces", map[string]string{"NAME": "prod", "STATUS": "Active"}, "prod"},
+	}
+	for _, c := range cases {
+		elem, key, err := svc.DeleteStub(job(c.pollaris, c.job, c.cmap))
+		if err != nil {
+			t.Fatal(c.pollaris, ":", c.job, ": ", err)
+		}
+		resource := elem.(*deleteResource)
+		if key != c.key || resource.Key != c.key || resource.ClusterName != "lab" {
+			t.Fatal(c.pollaris, ":", c.job, ": unexpected stub ", resource, " key ", key)
+		}
+	}
+
+	if _, _, err := svc.DeleteStub(job("kubernetes", "pods", map[string]string{"uid": "42"})); err == nil {
+		t.Fatal("expected an error for a delete job without key columns")
+	}
+
+	svc.HandleDelete(job("kubernetes", "pods", map[string]string{"namespace": "prod", "name": "db-0"}))
+	svc.HandleDelete(job("kubernetes", "pods", map[string]string{"uid": "42"}))
+	counters := svc.Metrics().Counters(parsing.MetricsByJob, "kubernetes.pods")
+	if counters == nil || counters.Deletes != 1 {
+		t.Fatal("expected one delete to be sent, got ", counters)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+func newDeltaDevice(sysName, vendor, physicalId string) *types2.NetworkDevice {
+	device := &types2.NetworkDevice{Id: "10.20.30.1"}
+	device.Equipmentinfo = &types2.EquipmentInfo{SysName: sysName, Vendor: vendor}
+	device.Physicals = map[string]*types2.Physical{"physical-0": {Id: physicalId}}
+	return device
+}
+
+// TestDeltaTracker verifies that only the changed fields and the primary key are sent,
+// that an unchanged object is not sent, and that a cleared field sends the whole object.
+func TestDeltaTracker(t *testing.T) {
+	deltas := parsing.NewDeltaTracker(0)
+	primaryKeys := []string{"Id"}
+
+	first := newDeltaDevice("r1", "cisco", "chassis")
+	obj, changed := deltas.Delta("mib2.system/10.20.30.1", first, primaryKeys)
+	if !changed || obj != first {
+		t.Fatal("expected the first object to be sent whole")
+	}
+
+	obj, changed = deltas.Delta("mib2.system/10.20.30.1", newDeltaDevice("r1", "cisco", "chassis"), primaryKeys)
+	if changed {
+		t.Fatal("expected an unchanged object not to be sent, got ", obj)
+	}
+
+	obj, changed = deltas.Delta("mib2.system/10.20.30.1", newDeltaDevice("r2", "cisco", "chassis"), primaryKeys)
+	if !changed {
+		t.Fatal("expected a changed object to be sent")
+	}
+	delta := obj.(*types2.NetworkDevice)
+	if delta.Id != "10.20.30.1" || delta.Equipmentinfo == nil || delta.Equipmentinfo.SysName != "r2" {
+		t.Fatal("expected the delta to hold the key and the changed sysName, got ", delta)
+	}
+	if delta.Equipmentinfo.Vendor != "" || len(delta.Physicals) != 0 {
+		t.Fatal("expected the delta to hold only the changed fields, got ", delta)
+	}
+
+	cleared := newDeltaDevice("r2", "", "chassis")
+	obj, changed = deltas.Delta("mib2.system/10.20.30.1", cleared, primaryKeys)
+	if !changed || obj != cleared {
+		t.Fatal("expected a cleared field to send the whole object")
+	}
+}
@@ -0,0 +1,132 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestDeviceClassifier verifies the device type, confidence and evidence of the built-in
+// classifier table, and that the table can be extended with JSON rules.
+func TestDeviceClassifier(t *testing.T) {
+	devices := []struct {
+		facts    *rules.DeviceFacts
+		expected types2.DeviceType
+	}{
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.25461.2.3.38", SysDescr: "Palo Alto Networks PA-3220 series firewall"},
+			types2.DeviceType_DEVICE_TYPE_FIREWALL},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.9.1.122", SysDescr: "Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 12.2(55)SE7", SysServices: 2},
+			types2.DeviceType_DEVICE_TYPE_SWITCH},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.2636.1.1.1.2.63", SysDescr: "Juniper Networks, Inc. ex4300-48t Ethernet Switch, kernel JUNOS 15.1R7.9"},
+			types2.DeviceType_DEVICE_TYPE_SWITCH},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.2636.1.1.1.2.29", SysDescr: "Juniper Networks, Inc. mx480 internet router, kernel JUNOS 18.4R3-S4.2", SysServices: 78,
+			Oids: []string{".1.3.6.1.2.1.15"}},
+			types2.DeviceType_DEVICE_TYPE_ROUTER},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.3375.2.1.3.4.43", SysDescr: "BIG-IP i5800"},
+			types2.DeviceType_DEVICE_TYPE_LOAD_BALANCER},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.41112.1.6", SysDescr: "Linux 3.3.8 #1 UAP-AC-Pro"},
+			types2.DeviceType_DEVICE_TYPE_ACCESS_POINT},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.789.2.5", SysDescr: "NetApp Release 9.8P4: Thu Mar 11 2021"},
+			types2.DeviceType_DEVICE_TYPE_STORAGE},
+		{&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.8072.3.2.10", SysDescr: "Linux gpu-node-01 5.15.0-91-generic #101-Ubuntu SMP", SysServices: 72,
+			Oids: []string{".1.3.6.1.2.1.25"}},
+			types2.DeviceType_DEVICE_TYPE_SERVER},
+	}
+	for _, device := range devices {
+		classification := rules.ClassifyDevice(device.facts)
+		if classification.Type != device.expected {
+			t.Error("expected ", device.expected, " for '", device.facts.SysDescr, "', got ", classification.Type,
+				" scores ", classification.Scores)
+			continue
+		}
+		if classification.Confidence <= 0 || classification.Confidence > 1 || len(classification.Evidence) == 0 {
+			t.Error("expected a confidence and evidence for '", device.facts.SysDescr, "', got ",
+				classification.Confidence, " ", classification.Evidence)
+		}
+	}
+
+	unknown := rules.ClassifyDevice(&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.99999.1", SysDescr: "Widget 3000"})
+	if unknown.Type != types2.DeviceType_DEVICE_TYPE_UNKNOWN || unknown.Confidence != 0 {
+		t.Fatal("expected an unknown device type without confidence, got ", unknown.Type, " ", unknown.Confidence)
+	}
+
+	saved := rules.ClassifierRules()
+	defer rules.SetClassifierRules(saved...)
+	custom, err := rules.ParseClassifierRules([]byte(`[{"name": "widget", "type": "GATEWAY", "weight": 80, "sysDescr": "\\bWidget \\d+"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rules.RegisterClassifierRules(custom...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	widget := rules.ClassifyDevice(&rules.DeviceFacts{SysObjectID: ".1.3.6.1.4.1.99999.1", SysDescr: "Widget 3000"})
+	if widget.Type != types2.DeviceType_DEVICE_TYPE_GATEWAY || len(widget.Evidence) != 1 || widget.Evidence[0] != "widget(+80)" {
+		t.Fatal("expected the registered rule to classify the widget, got ", widget.Type, " ", widget.Evidence)
+	}
+
+	_, err = rules.ParseClassifierRules([]byte(`[{"name": "bad", "type": "TOASTER", "weight": 10, "sysDescr": "toaster"}]`))
+	if err == nil {
+		t.Fatal("expected an unknown device type to be rejected")
+	}
+	_, err = rules.ParseClassifierRules([]byte(`[{"name": "empty", "type": "ROUTER", "weight": 10}]`))
+	if err == nil {
+		t.Fatal("expected a rule without conditions to be rejected")
+	}
+}
+
+// TestBuiltinClassifierRules verifies that the embedded classifier table parses, so a broken
+// table fails here and not only at the startup of a process using the package.
+func TestBuiltinClassifierRules(t *testing.T) {
+	builtin, err := rules.BuiltinClassifierRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builtin) == 0 {
+		t.Fatal("expected built-in classifier rules")
+	}
+	names := make(map[string]bool)
+	types := make(map[string]bool)
+	for _, entry := range builtin {
+		if names[entry.Name] {
+			t.Error("duplicate classifier rule name ", entry.Name)
+		}
+		names[entry.Name] = true
+		types[entry.Type] = true
+	}
+	for _, deviceType := range []string{"ACCESS_POINT", "LOAD_BALANCER", "STORAGE"} {
+		if !types[deviceType] {
+			t.Error("expected a built-in classifier rule for ", deviceType)
+		}
+	}
+
+	saved := rules.ClassifierRules()
+	defer rules.SetClassifierRules(saved...)
+	err = rules.RegisterClassifierRules(&rules.ClassifierRule{Name: "widget", Type: "GATEWAY", Weight: 80, SysDescr: "widget"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rules.SetClassifierRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.ClassifierRules()) != len(builtin) {
+		t.Fatal("expected SetClassifierRules without rules to restore the built-in table")
+	}
+}
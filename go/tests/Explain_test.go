@@ -0,0 +1,90 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	common2 "github.com/saichler/probler/go/prob/common"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestExplain verifies the traces recorded by Explain for a map poll and a table poll:
+// the resolved PropertyId, the raw and coerced values and the outcome of the set.
+func TestExplain(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := activateBootPollaris(vnic)
+
+	systemMib := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.2.1.1.1.0": encode("Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11"),
+		".1.3.6.1.2.1.1.2.0": encode(".1.3.6.1.4.1.9.1.1208"),
+		".1.3.6.1.2.1.1.5.0": encode("access-sw1"),
+	}}
+	job := &l8tpollaris.CJob{PollarisName: "boot01", JobName: "systemMib", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(systemMib)}
+	result, _ := parsing.Parser.Explain(job, &types2.NetworkDevice{Id: job.HostId}, res)
+	if result == nil {
+		res.Logger().Fail(t, "Explain returned no result for the systemMib job")
+		return
+	}
+	trace := traceFor(result, "networkdevice.equipmentinfo.sysname", "Set")
+	if trace == nil || trace.RawValue != "access-sw1" || trace.PropertyId != "networkdevice.equipmentinfo.sysname" ||
+		!trace.SetAttempted || !trace.SetOK {
+		res.Logger().Fail(t, "unexpected sysname trace ", trace)
+		return
+	}
+	for _, attrResult := range result.Attributes {
+		if !attrResult.Skipped && len(attrResult.Traces) != len(attrResult.Rules) {
+			res.Logger().Fail(t, "expected a trace per executed rule for ", attrResult.PropertyId)
+			return
+		}
+	}
+
+	ifTable := &l8tpollaris.CTable{Rows: map[int32]*l8tpollaris.CRow{
+		1: {Data: map[int32][]byte{1: encode(int64(1)), 2: encode("GigabitEthernet0/1"), 8: encode(int64(1))}},
+		2: {Data: map[int32][]byte{1: encode(int64(2)), 2: encode("GigabitEthernet0/2"), 8: encode(int64(2))}},
+	}}
+	job = &l8tpollaris.CJob{PollarisName: "boot03", JobName: "ifTable", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(ifTable)}
+	result, err := parsing.Parser.Explain(job, &types2.NetworkDevice{Id: job.HostId}, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	trace = traceFor(result, "networkdevice.physicals", "IfTableToPhysicals")
+	if trace == nil || trace.RawValue != "2 rows" || !trace.SetOK || trace.SetCount != 2 {
+		res.Logger().Fail(t, "unexpected ifTable trace ", trace)
+		return
+	}
+}
+
+// traceFor returns the trace of the named rule of the attribute targeting propertyId.
+func traceFor(result *parsing.ParseResult, propertyId, rule string) *rules.RuleTrace {
+	for _, attrResult := range result.Attributes {
+		if attrResult.PropertyId != propertyId {
+			continue
+		}
+		for _, trace := range attrResult.Traces {
+			if trace.Rule == rule {
+				return trace
+			}
+		}
+	}
+	return nil
+}
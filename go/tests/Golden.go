@@ -0,0 +1,188 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/saichler/l8parser/go/parser/replay"
+	"github.com/saichler/l8parser/go/parser/service"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// GoldenDir is the directory holding the recorded jobs and their expected outputs.
+// Each recorded job <name>.json or <name>.json.gz (a protojson CJob, as persisted by the
+// job store) has a companion <name>.golden.json holding the expected parsed output.
+const GoldenDir = "./testdata/golden/"
+
+// goldenSuffix is the file suffix of the expected outputs.
+const goldenSuffix = ".golden.json"
+
+// jobSuffixes are the file suffixes of the recorded jobs, compressed or not.
+var jobSuffixes = []string{".json.gz", ".json"}
+
+// GoldenJobs returns the recorded job files of the golden directory, sorted.
+func GoldenJobs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	jobs := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || jobSuffix(name) == "" || !replay.IsJobFile(name) || strings.HasSuffix(name, goldenSuffix) {
+			continue
+		}
+		jobs = append(jobs, filepath.Join(dir, name))
+	}
+	sort.Strings(jobs)
+	return jobs, nil
+}
+
+// GoldenFile returns the expected output file of a recorded job file.
+func GoldenFile(jobFile string) string {
+	return strings.TrimSuffix(jobFile, jobSuffix(jobFile)) + goldenSuffix
+}
+
+// jobSuffix returns the recorded job suffix of a file name, or "" if it is not a job file.
+func jobSuffix(name string) string {
+	for _, suffix := range jobSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// GoldenOutput renders a parse result as the generic JSON document stored in golden files:
+// {"element": {...}, "instances": [...]}. Rendering through a generic value keeps the
+// output stable regardless of protojson's formatting.
+func GoldenOutput(result *service.ParseResult) (interface{}, error) {
+	doc := make(map[string]interface{})
+	element, err := toGeneric(result.Element)
+	if err != nil {
+		return nil, err
+	}
+	doc["element"] = element
+	if len(result.Instances) > 0 {
+		instances := make([]interface{}, 0, len(result.Instances))
+		for _, inst := range result.Instances {
+			generic, err := toGeneric(inst)
+			if err != nil {
+				return nil, err
+			}
+			instances = append(instances, generic)
+		}
+		doc["instances"] = instances
+	}
+	return doc, nil
+}
+
+// WriteGolden writes the expected output of a recorded job.
+func WriteGolden(file string, doc interface{}) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0640)
+}
+
+// ReadGolden reads the expected output of a recorded job.
+func ReadGolden(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// DiffGolden compares the expected and actual documents field by field and returns one
+// line per difference, sorted by field path. An empty result means the documents match.
+func DiffGolden(expected, actual interface{}) []string {
+	exp := make(map[string]string)
+	act := make(map[string]string)
+	flatten("", expected, exp)
+	flatten("", actual, act)
+
+	diffs := make([]string, 0)
+	for path, expValue := range exp {
+		actValue, ok := act[path]
+		if !ok {
+			diffs = append(diffs, "- "+path+" = "+expValue)
+		} else if actValue != expValue {
+			diffs = append(diffs, "~ "+path+" = "+expValue+" -> "+actValue)
+		}
+	}
+	for path, actValue := range act {
+		if _, ok := exp[path]; !ok {
+			diffs = append(diffs, "+ "+path+" = "+actValue)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i][2:] < diffs[j][2:]
+	})
+	return diffs
+}
+
+// flatten turns a generic JSON document into leaf path -> value pairs,
+// e.g. "element.physicals.physical-0.ports[0].id" -> "\"eth0\"".
+func flatten(prefix string, value interface{}, result map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, child, result)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(prefix+"["+strconv.Itoa(i)+"]", child, result)
+		}
+	default:
+		data, _ := json.Marshal(v)
+		result[prefix] = string(data)
+	}
+}
+
+// toGeneric converts a parsed object into a generic JSON value.
+func toGeneric(any interface{}) (interface{}, error) {
+	var data []byte
+	var err error
+	if msg, ok := any.(proto.Message); ok {
+		data, err = protojson.Marshal(msg)
+	} else {
+		data, err = json.Marshal(any)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
@@ -0,0 +1,136 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/replay"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	common2 "github.com/saichler/probler/go/prob/common"
+)
+
+// update rewrites the golden files from the current parser output:
+// go test ./tests/ -run TestGolden -update
+var update = flag.Bool("update", false, "update the golden files of the recorded jobs")
+
+// TestGolden replays every recorded job of GoldenDir offline, without a topology,
+// and diffs the parsed output against its golden file field by field.
+func TestGolden(t *testing.T) {
+	jobs, err := GoldenJobs(GoldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) == 0 {
+		t.Skip("no recorded jobs in ", GoldenDir)
+	}
+
+	replay.RegisterBootModels()
+	replayer := replay.NewReplayer(replay.NewResources(), false)
+	for _, jobFile := range jobs {
+		t.Run(jobFile, func(t *testing.T) {
+			diffs := checkGolden(t, replayer, jobFile, *update)
+			if len(diffs) > 0 {
+				t.Error(len(diffs), " fields changed for ", jobFile, ":\n", strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
+
+// TestGoldenHarness records a gzipped job with the job store, as ParsingService does, and
+// verifies the golden flow over it: the job is found, -update writes its golden file, an
+// unchanged parse has no diffs and a changed golden field is reported.
+func TestGoldenHarness(t *testing.T) {
+	dir := t.TempDir()
+	store := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, Compress: true})
+	systemMib := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.2.1.1.1.0": encode("Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11"),
+		".1.3.6.1.2.1.1.2.0": encode(".1.3.6.1.4.1.9.1.1208"),
+		".1.3.6.1.2.1.1.5.0": encode("access-sw1"),
+	}}
+	job := &l8tpollaris.CJob{PollarisName: "boot01", JobName: "systemMib", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(systemMib)}
+	err := store.Save(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := GoldenJobs(dir + "/" + job.TargetId)
+	if err != nil || len(jobs) != 1 || !strings.HasSuffix(jobs[0], ".json.gz") {
+		t.Fatal("expected the recorded .json.gz job and not the index, got ", jobs, " ", err)
+	}
+
+	replay.RegisterBootModels()
+	replayer := replay.NewReplayer(replay.NewResources(), false)
+	checkGolden(t, replayer, jobs[0], true)
+	if diffs := checkGolden(t, replayer, jobs[0], false); len(diffs) != 0 {
+		t.Fatal("expected no diffs against the golden file just written, got ", diffs)
+	}
+
+	goldenFile := GoldenFile(jobs[0])
+	expected, err := ReadGolden(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	element := expected.(map[string]interface{})["element"].(map[string]interface{})
+	element["equipmentinfo"].(map[string]interface{})["sysName"] = "changed"
+	err = WriteGolden(goldenFile, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs := checkGolden(t, replayer, jobs[0], false)
+	if len(diffs) != 1 || !strings.Contains(diffs[0], "element.equipmentinfo.sysName") {
+		t.Fatal("expected the changed sysName to be reported, got ", diffs)
+	}
+}
+
+// checkGolden replays a recorded job and returns its diffs against the golden file,
+// writing the golden file instead when update is true.
+func checkGolden(t *testing.T, replayer *replay.Replayer, jobFile string, update bool) []string {
+	result := replayer.RunFile(jobFile, "")
+	if result.Parse == nil {
+		t.Fatal("failed to parse ", jobFile, ": ", result.Error)
+	}
+	if result.Error != nil {
+		t.Log("parse errors: ", result.Error.Error())
+	}
+	actual, err := GoldenOutput(result.Parse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goldenFile := GoldenFile(jobFile)
+	if update {
+		err = WriteGolden(goldenFile, actual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nil
+	}
+
+	expected, err := ReadGolden(goldenFile)
+	if os.IsNotExist(err) {
+		t.Fatal("missing golden file ", goldenFile, ", run with -update to create it")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DiffGolden(expected, actual)
+}
@@ -0,0 +1,64 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestInstanceTracker verifies that an instance missing from the table is only reported
+// after the grace period, as a key only stub, and that a returning instance is kept.
+func TestInstanceTracker(t *testing.T) {
+	tracker := parsing.NewInstanceTracker(50 * time.Millisecond)
+	primaryKeys := []string{"Id"}
+	instances := func(ids ...string) []interface{} {
+		result := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			device := &types2.NetworkDevice{Id: id}
+			device.Equipmentinfo = &types2.EquipmentInfo{SysName: "node-" + id}
+			result = append(result, device)
+		}
+		return result
+	}
+	table := "K8sPod/cluster1/K8sPod.pods"
+
+	if gone := tracker.Update(table, instances("a", "b", "c"), primaryKeys); len(gone) != 0 {
+		t.Fatal("expected nothing gone on the first table")
+	}
+	if gone := tracker.Update(table, instances("a", "c"), primaryKeys); len(gone) != 0 {
+		t.Fatal("expected b to be kept during the grace period")
+	}
+	// b flaps back, then c goes away
+	if gone := tracker.Update(table, instances("a", "b"), primaryKeys); len(gone) != 0 {
+		t.Fatal("expected nothing gone during the grace period")
+	}
+	time.Sleep(60 * time.Millisecond)
+	gone := tracker.Update(table, instances("a", "b"), primaryKeys)
+	if len(gone) != 1 {
+		t.Fatal("expected 1 instance gone, got ", len(gone))
+	}
+	stub := gone[0].(*types2.NetworkDevice)
+	if stub.Id != "c" || stub.Equipmentinfo != nil {
+		t.Fatal("expected a key only stub of c, got ", stub)
+	}
+	if tracker.Known(table) != 2 {
+		t.Fatal("expected 2 known instances, got ", tracker.Known(table))
+	}
+}
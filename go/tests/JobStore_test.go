@@ -0,0 +1,175 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"google.golang.org/protobuf/proto"
+)
+
+func storeJob(jobName, host string, ended int64) *l8tpollaris.CJob {
+	return &l8tpollaris.CJob{PollarisName: "mib2", JobName: jobName, HostId: host, TargetId: host,
+		Ended: ended, Result: []byte("result-" + jobName)}
+}
+
+// TestFileJobStoreRoundTrip verifies that a saved job loads back unchanged, gzipped or
+// not, that index.json is rewritten on every save and that a new store reads it from disk.
+func TestFileJobStoreRoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		store := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, Compress: compress})
+		first := storeJob("ifTable", "10.20.30.1", 100)
+		second := storeJob("systemMib", "10.20.30.1", 200)
+		for _, job := range []*l8tpollaris.CJob{first, second} {
+			if err := store.Save(job); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		loaded, err := store.Load("mib2", "ifTable", "10.20.30.1", "10.20.30.1")
+		if err != nil || !proto.Equal(loaded, first) {
+			t.Fatal("expected the saved job back, compress=", compress, ": ", err)
+		}
+
+		records, err := store.History("mib2", "ifTable", "10.20.30.1", "10.20.30.1")
+		if err != nil || len(records) != 1 || records[0].Compressed != compress || records[0].Ended != 100 {
+			t.Fatal("unexpected history ", records, " ", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "10.20.30.1", records[0].File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gzipped := len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+		if gzipped != compress || strings.HasSuffix(records[0].File, ".json.gz") != compress ||
+			records[0].Size != len(data) {
+			t.Fatal("unexpected job file ", records[0].File, " compress=", compress)
+		}
+
+		data, err = os.ReadFile(filepath.Join(dir, "10.20.30.1", "index.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		index := make([]*parsing.JobRecord, 0)
+		if err = json.Unmarshal(data, &index); err != nil || len(index) != 2 {
+			t.Fatal("expected both jobs in index.json, got ", len(index), " ", err)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "10.20.30.1", "index.json.tmp")); !os.IsNotExist(err) {
+			t.Fatal("expected no temporary index to be left behind")
+		}
+
+		reopened := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, Compress: compress})
+		loaded, err = reopened.Load("mib2", "systemMib", "10.20.30.1", "10.20.30.1")
+		if err != nil || !proto.Equal(loaded, second) {
+			t.Fatal("expected a new store to load the index from disk: ", err)
+		}
+	}
+}
+
+// TestFileJobStoreRetention verifies MaxPerKey and that MaxAge ages out the jobs of every
+// key of the target, not only of the key being saved, while keeping each key's latest job.
+func TestFileJobStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	store := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, MaxPerKey: 2})
+	for i := int64(0); i < 4; i++ {
+		if err := store.Save(storeJob("ifTable", "10.20.30.1", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, _ := store.History("mib2", "ifTable", "10.20.30.1", "10.20.30.1")
+	if len(records) != 2 || records[0].Ended != 2 || records[1].Ended != 3 {
+		t.Fatal("expected the 2 most recent jobs to be kept, got ", records)
+	}
+	if files := jobFiles(t, filepath.Join(dir, "10.20.30.1")); files != 2 {
+		t.Fatal("expected the dropped job files to be deleted, found ", files)
+	}
+
+	dir = t.TempDir()
+	store = parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, MaxAge: 50 * time.Millisecond})
+	store.Save(storeJob("entityMib", "10.20.30.1", 1))
+	store.Save(storeJob("entityMib", "10.20.30.1", 2))
+	store.Save(storeJob("bgpTable", "10.20.30.1", 1))
+	time.Sleep(100 * time.Millisecond)
+	store.Save(storeJob("ifTable", "10.20.30.1", 3))
+
+	records, _ = store.History("mib2", "entityMib", "10.20.30.1", "10.20.30.1")
+	if len(records) != 1 || records[0].Ended != 2 {
+		t.Fatal("expected the old jobs of a key not being saved to age out, got ", records)
+	}
+	records, _ = store.History("mib2", "bgpTable", "10.20.30.1", "10.20.30.1")
+	if len(records) != 1 {
+		t.Fatal("expected the latest job of a key to be kept regardless of age")
+	}
+	if files := jobFiles(t, filepath.Join(dir, "10.20.30.1")); files != 3 {
+		t.Fatal("expected 3 job files, found ", files)
+	}
+}
+
+// TestFileJobStorePaths verifies that target and host ids with path separators or ".."
+// are kept inside the store directory.
+func TestFileJobStorePaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "store")
+	store := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir})
+	for _, id := range []string{"..", "../escape", "..\\escape", "."} {
+		job := storeJob("ifTable", id, 1)
+		if err := store.Save(job); err != nil {
+			t.Fatal(err)
+		}
+		records, err := store.History("mib2", "ifTable", id, id)
+		if err != nil || len(records) != 1 {
+			t.Fatal("expected the job of ", id, " to be recorded: ", err)
+		}
+		if strings.Contains(records[0].File, "..") {
+			t.Fatal("unexpected file name ", records[0].File, " for ", id)
+		}
+		if loaded, err := store.LoadRecord(records[0]); err != nil || !proto.Equal(loaded, job) {
+			t.Fatal("expected the job of ", id, " to load back: ", err)
+		}
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "store" {
+		t.Fatal("expected nothing to be written outside of the store directory")
+	}
+	entries, _ = os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() == ".." || entry.Name() == "." || !entry.IsDir() {
+			t.Fatal("unexpected entry ", entry.Name(), " in the store directory")
+		}
+	}
+}
+
+// jobFiles counts the job files of a target directory.
+func jobFiles(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, entry := range entries {
+		if entry.Name() != "index.json" {
+			count++
+		}
+	}
+	return count
+}
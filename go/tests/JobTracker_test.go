@@ -0,0 +1,97 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestJobTracker verifies that out of order jobs are stale, identical results are
+// unchanged until the refresh interval passes, and other keys are tracked separately.
+func TestJobTracker(t *testing.T) {
+	tracker := parsing.NewJobTracker(50 * time.Millisecond)
+	job := func(hostId string, ended int64, result string) *l8tpollaris.CJob {
+		return &l8tpollaris.CJob{PollarisName: "mib2", JobName: "system", HostId: hostId,
+			Ended: ended, Result: []byte(result)}
+	}
+
+	steps := []struct {
+		job      *l8tpollaris.CJob
+		expected parsing.JobDecision
+	}{
+		{job("10.20.30.1", 100, "a"), parsing.JobApply},
+		{job("10.20.30.1", 90, "b"), parsing.JobStale},
+		{job("10.20.30.1", 110, "a"), parsing.JobUnchanged},
+		// The unchanged job advanced the last Ended
+		{job("10.20.30.1", 105, "c"), parsing.JobStale},
+		{job("10.20.30.1", 120, "c"), parsing.JobApply},
+		{job("10.20.30.2", 50, "c"), parsing.JobApply},
+	}
+	for i, step := range steps {
+		decision := tracker.Check(step.job)
+		if decision != step.expected {
+			t.Fatal("step ", i, ": expected ", step.expected.String(), " but got ", decision.String())
+		}
+	}
+	if tracker.Stale() != 2 || tracker.Unchanged() != 1 {
+		t.Fatal("unexpected counters, stale ", tracker.Stale(), " unchanged ", tracker.Unchanged())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if tracker.Check(job("10.20.30.1", 130, "c")) != parsing.JobApply {
+		t.Fatal("expected an unchanged job to be applied after the refresh interval")
+	}
+
+	// a job failing after the first check of its key leaves nothing applied
+	first := job("10.20.30.3", 10, "a")
+	tracker.Check(first)
+	tracker.Forget(first)
+	if tracker.Check(job("10.20.30.3", 5, "a")) != parsing.JobApply {
+		t.Fatal("expected a key without an applied job to apply any job")
+	}
+}
+
+// TestJobTrackerForgetKeepsLastApplied verifies that forgetting a failed job restores the
+// last applied job, so an older job arriving late is still stale.
+func TestJobTrackerForgetKeepsLastApplied(t *testing.T) {
+	tracker := parsing.NewJobTracker(0)
+	job := func(ended int64, result string) *l8tpollaris.CJob {
+		return &l8tpollaris.CJob{PollarisName: "mib2", JobName: "system", HostId: "10.20.30.1",
+			Ended: ended, Result: []byte(result)}
+	}
+	if tracker.Check(job(20, "a")) != parsing.JobApply {
+		t.Fatal("expected the first job to be applied")
+	}
+	failed := job(30, "b")
+	if tracker.Check(failed) != parsing.JobApply {
+		t.Fatal("expected the changed job to be applied")
+	}
+	tracker.Forget(failed)
+
+	if decision := tracker.Check(job(10, "c")); decision != parsing.JobStale {
+		t.Fatal("expected a job older than the last applied one to be stale, got ", decision.String())
+	}
+	if decision := tracker.Check(job(25, "a")); decision != parsing.JobUnchanged {
+		t.Fatal("expected the result of the last applied job to be unchanged, got ", decision.String())
+	}
+	if decision := tracker.Check(job(40, "b")); decision != parsing.JobApply {
+		t.Fatal("expected the result of the failed job to be applied again, got ", decision.String())
+	}
+}
@@ -0,0 +1,131 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+type compositeKeyModel struct {
+	ClusterName string
+	Namespace   string
+	Links       string
+	Name        string
+	Uid         int64
+}
+
+// TestKeySources verifies that each primary key field is set from its job attribute and
+// that a missing job attribute is an error rather than a panic.
+func TestKeySources(t *testing.T) {
+	job := &l8tpollaris.CJob{HostId: "cluster1", TargetId: "cluster1-0", LinksId: "K8s",
+		Ended: 1700000000, Arguments: map[string]string{"namespace": "default"}}
+	keySources := []*parsing.KeySource{
+		{Field: "ClusterName", Kind: parsing.KeyFromHostId},
+		{Field: "Namespace", Kind: parsing.KeyFromArgument, Name: "namespace"},
+		{Field: "Links", Kind: parsing.KeyFromLinksId},
+		{Field: "Name", Kind: parsing.KeyFromOutput, Name: "k8spod.name"},
+		{Field: "Uid", Kind: parsing.KeyFromOutput, Name: "k8spod.uid"},
+	}
+
+	elem := &compositeKeyModel{}
+	if err := parsing.SetKeys(elem, job, keySources); err != nil {
+		t.Fatal(err)
+	}
+	if elem.ClusterName != "cluster1" || elem.Namespace != "default" || elem.Links != "K8s" || elem.Name != "" {
+		t.Fatal("unexpected keys ", elem)
+	}
+
+	// the output keys are set from the values the rules of the attributes produced
+	result := &parsing.ParseResult{Attributes: []*parsing.AttributeResult{
+		{PropertyId: "k8spod.status", Output: "Running"},
+		{PropertyId: "k8spod.name", Output: "db-0"},
+		{PropertyId: "k8spod.uid", Output: "42"},
+	}}
+	if err := parsing.SetParsedKeys(elem, result, keySources); err != nil {
+		t.Fatal(err)
+	}
+	if elem.ClusterName != "cluster1" || elem.Namespace != "default" || elem.Name != "db-0" || elem.Uid != 42 {
+		t.Fatal("unexpected composite key ", elem)
+	}
+	result.Attributes[1].Output = nil
+	if err := parsing.SetParsedKeys(&compositeKeyModel{}, result, keySources); err == nil {
+		t.Fatal("expected an error for an attribute without output")
+	}
+
+	delete(job.Arguments, "namespace")
+	if err := parsing.SetKeys(&compositeKeyModel{}, job, keySources); err == nil {
+		t.Fatal("expected an error for a missing namespace argument")
+	}
+	missing := []*parsing.KeySource{{Field: "Name", Kind: parsing.KeyFromTargetId}}
+	if err := parsing.SetKeys(&compositeKeyModel{}, job, missing); err == nil {
+		t.Fatal("expected an error for a missing key field")
+	}
+}
+
+// TestKeySourcesWithoutPrimaryKeys verifies that a service without primary keys or key
+// sources fails to activate instead of panicking.
+func TestKeySourcesWithoutPrimaryKeys(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	svc := &parsing.ParsingService{}
+	sla := ifs.NewServiceLevelAgreement(svc, "NoKeysTest", 0, true, nil)
+	sla.SetServiceItem(&compositeKeyModel{})
+	sla.SetArgs(false)
+	if err := svc.Activate(sla, vnic); err == nil {
+		t.Fatal("expected the activation without primary keys to fail")
+	}
+}
+
+// TestParsedKeySource verifies that a KeyFromOutput key is set from the value a rule
+// produced while parsing a job.
+func TestParsedKeySource(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+	res.Introspector().Inspect(&types2.NetworkDevice{})
+
+	input := &l8tpollaris.CMap{Data: map[string][]byte{".1.3.6.1.2.1.1.5.0": encode("r1")}}
+	job := &l8tpollaris.CJob{PollarisName: "test", JobName: "keys", HostId: "10.20.30.1", LinksId: "NetDev",
+		Result: encode(input)}
+	poll := &l8tpollaris.L8Poll{Name: "keys", Attributes: []*l8tpollaris.L8PAttribute{
+		{PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.sysname"},
+			Rules: []*l8tpollaris.L8PRule{{Name: "Set", Params: map[string]*l8tpollaris.L8PParameter{
+				"from": {Name: "from", Value: ".1.3.6.1.2.1.1.5.0"}}}}},
+	}}
+	result, err := parsing.Parser.ParsePoll(job, poll, "networkdevice", &types2.NetworkDevice{Id: job.HostId}, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySources := []*parsing.KeySource{
+		{Field: "ClusterName", Kind: parsing.KeyFromHostId},
+		{Field: "Name", Kind: parsing.KeyFromOutput, Name: "networkdevice.equipmentinfo.sysname"},
+	}
+	elem := &compositeKeyModel{}
+	if err = parsing.SetKeys(elem, job, keySources); err != nil {
+		t.Fatal(err)
+	}
+	if err = parsing.SetParsedKeys(elem, result, keySources); err != nil {
+		t.Fatal(err)
+	}
+	if elem.ClusterName != "10.20.30.1" || elem.Name != "r1" {
+		t.Fatal("unexpected composite key ", elem)
+	}
+}
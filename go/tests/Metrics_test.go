@@ -0,0 +1,81 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestMetrics verifies the job, rule and target counters and the latency histograms
+// for a parsed, a partially parsed and a failed job.
+func TestMetrics(t *testing.T) {
+	metrics := parsing.NewMetrics()
+	job := &l8tpollaris.CJob{PollarisName: "mib2", JobName: "system", TargetId: "10.20.30.1"}
+
+	ok := &parsing.ParseResult{Duration: 3 * time.Millisecond, Instances: []interface{}{1, 2}}
+	ok.Attributes = []*parsing.AttributeResult{
+		{Rules: []string{"Contains", "Set"}, RuleDurations: []time.Duration{time.Microsecond, 2 * time.Millisecond}},
+	}
+	partial := &parsing.ParseResult{Duration: 20 * time.Millisecond}
+	partial.Attributes = []*parsing.AttributeResult{
+		{Rules: []string{"Set"}, RuleDurations: []time.Duration{time.Microsecond}, FailedRule: "Set",
+			Error: errors.New("cannot set")},
+	}
+	partialErr := &parsing.ParseErrors{Errors: []*parsing.AttributeError{{Rule: "Set", Err: errors.New("cannot set")}}}
+
+	for i := 0; i < 3; i++ {
+		metrics.JobReceived(job)
+	}
+	metrics.JobParsed(job, ok, nil)
+	metrics.Patched(job, 2)
+	metrics.JobParsed(job, partial, partialErr)
+	metrics.Patched(job, 1)
+	metrics.JobFailed(job)
+
+	for _, dimension := range [][]string{{parsing.MetricsByPollaris, "mib2"},
+		{parsing.MetricsByJob, "mib2.system"}, {parsing.MetricsByTarget, "10.20.30.1"}} {
+		c := metrics.Counters(dimension[0], dimension[1])
+		if c == nil {
+			t.Fatal("no counters for ", dimension)
+		}
+		if c.JobsReceived != 3 || c.JobsParsed != 2 || c.JobsFailed != 1 || c.Patches != 3 || c.Instances != 2 {
+			t.Fatalf("unexpected counters for %v: %+v", dimension, c)
+		}
+		if c.RuleExecutions != 3 || c.RuleFailures != 1 {
+			t.Fatalf("unexpected rule counters for %v: %+v", dimension, c)
+		}
+		// 3ms falls in the 5ms bucket, 20ms in the 50ms bucket
+		if c.Latency.Count != 2 || c.Latency.Buckets[1] != 1 || c.Latency.Buckets[3] != 1 {
+			t.Fatalf("unexpected latency for %v: %+v", dimension, c.Latency)
+		}
+	}
+
+	set := metrics.Counters(parsing.MetricsByRule, "Set")
+	if set == nil || set.RuleExecutions != 2 || set.RuleFailures != 1 || set.Latency.Count != 2 {
+		t.Fatalf("unexpected Set rule counters: %+v", set)
+	}
+
+	table := metrics.Table()
+	// pollaris, job, target and two rules
+	if len(table.Rows) != 5 {
+		t.Fatal("expected 5 metric rows, got ", len(table.Rows))
+	}
+}
@@ -0,0 +1,127 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestNeighborLinks verifies the LLDP and CDP neighbors parsed from MIB walks, and that
+// they are set as links on the NetworkLinks list of a model.
+func TestNeighborLinks(t *testing.T) {
+	lldp := rules.LldpNeighbors(map[string]interface{}{
+		".1.0.8802.1.1.2.1.3.7.1.2.1":        int32(5),
+		".1.0.8802.1.1.2.1.3.7.1.3.1":        "GigabitEthernet0/1",
+		".1.0.8802.1.1.2.1.3.7.1.2.2":        int32(3),
+		".1.0.8802.1.1.2.1.3.7.1.3.2":        string([]byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5f}),
+		".1.0.8802.1.1.2.1.3.7.1.4.2":        "uplink",
+		".1.0.8802.1.1.2.1.4.1.1.4.0.2.1":    int32(4),
+		".1.0.8802.1.1.2.1.4.1.1.5.0.2.1":    string([]byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}),
+		".1.0.8802.1.1.2.1.4.1.1.6.0.2.1":    int32(5),
+		".1.0.8802.1.1.2.1.4.1.1.7.0.2.1":    "Ethernet1",
+		".1.0.8802.1.1.2.1.4.1.1.9.0.2.1":    "core-sw1",
+		".1.0.8802.1.1.2.1.4.1.1.12.0.2.1":   string([]byte{0x28, 0x00}),
+		".1.0.8802.1.1.2.1.4.1.1.4.100.1.3":  int32(5),
+		".1.0.8802.1.1.2.1.4.1.1.5.100.1.3":  string([]byte{0x01, 10, 0, 0, 1}),
+		".1.0.8802.1.1.2.1.4.1.1.7.100.1.3":  "xe-0/0/1",
+		".1.0.8802.1.1.2.1.4.1.1.9.100.1.3":  "edge-r1",
+		".1.0.8802.1.1.2.1.4.1.1.12.100.1.3": "08 00",
+		".1.0.8802.1.1.2.1.4.1.1.5.50.1.3":   "stale",
+	})
+	if len(lldp) != 2 {
+		t.Fatal("expected 2 LLDP neighbors, got ", len(lldp))
+	}
+	first := lldp[0]
+	if first.LocalPort != "GigabitEthernet0/1" || first.RemoteChassisId != "10.0.0.1" || first.RemotePort != "xe-0/0/1" ||
+		first.RemoteSystemName != "edge-r1" || !reflect.DeepEqual(first.Capabilities, []string{"router"}) {
+		t.Fatal("unexpected first LLDP neighbor ", *first)
+	}
+	second := lldp[1]
+	if second.LocalPort != "uplink" || second.RemoteChassisId != "00:1a:2b:3c:4d:5e" || second.RemotePort != "Ethernet1" ||
+		second.RemoteSystemName != "core-sw1" || !reflect.DeepEqual(second.Capabilities, []string{"bridge", "router"}) {
+		t.Fatal("unexpected second LLDP neighbor ", *second)
+	}
+
+	cdp := rules.CdpNeighbors(map[string]interface{}{
+		"1.3.6.1.4.1.9.9.23.1.1.1.1.6.10":   "GigabitEthernet1/0/10",
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.6.10.3": "dist-sw2.example.com",
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.7.10.3": "TenGigabitEthernet1/1/1",
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.9.10.3": string([]byte{0, 0, 0, 0x29}),
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.6.2.1":  "SEP001122334455",
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.7.2.1":  "Port 1",
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.9.2.1":  string([]byte{0, 0, 0x04, 0x90}),
+		"1.3.6.1.4.1.9.9.23.1.2.1.1.17.2.1": "ip-phone-12",
+	})
+	if len(cdp) != 2 {
+		t.Fatal("expected 2 CDP neighbors, got ", len(cdp))
+	}
+	if cdp[0].LocalPort != "ifIndex 2" || cdp[0].RemoteSystemName != "ip-phone-12" ||
+		!reflect.DeepEqual(cdp[0].Capabilities, []string{"host", "phone", "macRelay"}) {
+		t.Fatal("unexpected first CDP neighbor ", *cdp[0])
+	}
+	if cdp[1].LocalPort != "GigabitEthernet1/0/10" || cdp[1].RemoteChassisId != "dist-sw2.example.com" ||
+		cdp[1].RemoteSystemName != "dist-sw2.example.com" || cdp[1].RemotePort != "TenGigabitEthernet1/1/1" ||
+		!reflect.DeepEqual(cdp[1].Capabilities, []string{"router", "switch", "igmp"}) {
+		t.Fatal("unexpected second CDP neighbor ", *cdp[1])
+	}
+
+	device := &types2.NetworkDevice{}
+	unset, err := rules.SetNeighborLinks(device, lldp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range unset {
+		if name == rules.NeighborId || name == rules.NeighborLocalPort || name == rules.NeighborRemotePort ||
+			name == rules.NeighborRemoteSystemName {
+			t.Fatal("expected NetworkLink to have a field for ", name, ", unset ", unset)
+		}
+	}
+	if len(device.NetworkLinks) != 2 {
+		t.Fatal("expected 2 links, got ", len(device.NetworkLinks))
+	}
+	link := device.NetworkLinks[1]
+	if link.LinkId != lldp[1].Id() || link.FromInterface != "uplink" || link.ToInterface != "Ethernet1" ||
+		link.ToNode != "core-sw1" {
+		t.Fatal("unexpected link ", link)
+	}
+
+	// the same neighbor seen over CDP, on the same local port with the same chassis ID,
+	// replaces the LLDP link instead of adding another one
+	overCdp := &rules.Neighbor{Protocol: "cdp", LocalPort: "uplink", RemoteChassisId: "00:1a:2b:3c:4d:5e",
+		RemotePort: "Ethernet1/1", RemoteSystemName: "core-sw1"}
+	if overCdp.Id() != lldp[1].Id() {
+		t.Fatal("expected the link ID not to depend on the protocol")
+	}
+	_, err = rules.SetNeighborLinks(device, append([]*rules.Neighbor{overCdp}, cdp...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(device.NetworkLinks) != 4 {
+		t.Fatal("expected the neighbor seen over CDP to replace its LLDP link, got ", len(device.NetworkLinks), " links")
+	}
+	link = device.NetworkLinks[1]
+	if link.LinkId != lldp[1].Id() || link.ToInterface != "Ethernet1/1" {
+		t.Fatal("unexpected replaced link ", link)
+	}
+	_, err = rules.SetNeighborLinks(device, lldp, [][]string{{"platform", "tonode"}})
+	if err == nil {
+		t.Fatal("expected an unknown neighbor field to be rejected")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestOidRegistry verifies the longest prefix match of the sysObjectID registry, the
+// IANA enterprise names and the prefixes registered at runtime.
+func TestOidRegistry(t *testing.T) {
+	if p := boot.GetPollarisByOid("1.3.6.1.4.1.9.1.122"); p.Name != "cisco-switch" {
+		t.Fatal("expected the Catalyst 2960 to use the cisco-switch pollaris, got ", p.Name)
+	}
+	if p := boot.GetPollarisByOid(".1.3.6.1.4.1.9.1.1220"); p.Name != "cisco-router" {
+		t.Fatal("expected .1.3.6.1.4.1.9.1.1220 not to match the .1.3.6.1.4.1.9.1.122 prefix, got ", p.Name)
+	}
+	if p := boot.GetPollarisByOid(".1.3.6.1.4.1.99999.1"); p.Name != boot.CreateBoot03().Name {
+		t.Fatal("expected an unknown sysObjectID to use the generic pollaris, got ", p.Name)
+	}
+
+	entry := rules.LookupOid(".1.3.6.1.4.1.9.1.717.5")
+	if entry == nil || entry.Vendor != "Cisco" || entry.Family != "Catalyst" {
+		t.Fatal("expected the Catalyst entry with the Cisco vendor, got ", entry)
+	}
+	if vendor := rules.VendorOf(".1.3.6.1.4.1.3375.2.1.3.4.43"); vendor != "F5 Networks" {
+		t.Fatal("expected the IANA enterprise name F5 Networks, got ", vendor)
+	}
+	if vendor := rules.VendorOf(".1.3.6.1.2.1.1"); vendor != "" {
+		t.Fatal("expected no vendor outside the enterprises sub-tree, got ", vendor)
+	}
+
+	// rules.RegisterOid, unlike boot.RegisterPollarisForOid, does not add the pollaris to
+	// boot.GetAllPolarisModels used by the other tests.
+	custom := &l8tpollaris.L8Pollaris{Name: "test-oid-registry", Polling: map[string]*l8tpollaris.L8Poll{}}
+	rules.RegisterOid(&rules.OidEntry{Prefix: ".1.3.6.1.4.1.99998", Vendor: "Acme", Family: "Widget",
+		Pollaris: func() *l8tpollaris.L8Pollaris { return custom }})
+	if p := boot.GetPollarisByOid(".1.3.6.1.4.1.99998.3.1"); p != custom {
+		t.Fatal("expected the registered pollaris, got ", p.Name)
+	}
+	if vendor := rules.VendorOf(".1.3.6.1.4.1.99998.3.1"); vendor != "Acme" {
+		t.Fatal("expected the registered vendor, got ", vendor)
+	}
+}
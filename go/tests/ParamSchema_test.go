@@ -0,0 +1,123 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+func paramsOf(values map[string]string) map[string]*l8tpollaris.L8PParameter {
+	params := make(map[string]*l8tpollaris.L8PParameter)
+	for name, value := range values {
+		params[name] = &l8tpollaris.L8PParameter{Name: name, Value: value}
+	}
+	return params
+}
+
+// TestParseParams verifies the typed values of each parameter type, the defaults and
+// the failures on missing required parameters and invalid values.
+func TestParseParams(t *testing.T) {
+	schema := []*rules.ParamSpec{
+		{Name: "count", Type: rules.ParamInt, Required: true},
+		{Name: "columns", Type: rules.ParamIntList},
+		{Name: "kind", Type: rules.ParamEnum, EnumValues: []string{"set", "ts"}, Default: "set"},
+		{Name: "oid", Type: rules.ParamOID},
+		{Name: "path", Type: rules.ParamJSONPath},
+		{Name: "flag", Type: rules.ParamBool},
+		{Name: "patterns", Type: rules.ParamRegexList},
+	}
+	typed, err := rules.ParseParams(schema, paramsOf(map[string]string{
+		"count": "3", "columns": "0, 2", "oid": ".1.3.6.1.2.1.1.5.0", "path": "devices.0.name",
+		"flag": "true", "patterns": "Version (\\S+)\n\n(\\d+)", "unknown": "ignored"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typed.Int("count") != 3 || len(typed.IntList("columns")) != 2 || typed.IntList("columns")[1] != 2 ||
+		typed.String("kind") != "set" || typed.String("oid") != ".1.3.6.1.2.1.1.5.0" ||
+		typed.String("path") != "devices.0.name" || !typed.Bool("flag") || len(typed.RegexList("patterns")) != 2 {
+		t.Fatal("unexpected typed params")
+	}
+	if typed.Has("unknown") {
+		t.Fatal("expected a parameter missing from the schema to be ignored")
+	}
+
+	invalid := []map[string]string{
+		{},
+		{"count": "three"},
+		{"count": "3", "columns": "0,x"},
+		{"count": "3", "oid": "1.3.x"},
+		{"count": "3", "path": ".devices"},
+		{"count": "3", "flag": "maybe"},
+		{"count": "3", "patterns": "("},
+	}
+	for _, values := range invalid {
+		if _, err = rules.ParseParams(schema, paramsOf(values)); err == nil {
+			t.Fatal("expected an error for ", values)
+		}
+	}
+}
+
+// TestParseParamsFallbacks verifies that, as before the rules had a schema, an invalid
+// value falls back to the parameter's default and malformed mapping entries are skipped
+// at run time, while the validator's ParseParamValue still reports them.
+func TestParseParamsFallbacks(t *testing.T) {
+	gpuTable := (&rules.SnmpGpuTable{}).ParamSchema()
+	typed, err := rules.ParseParams(gpuTable, paramsOf(map[string]string{
+		"oid_base": ".1.3.6.1.4.1.53246.1.1.1.1",
+		"mapping":  "1:devicename:set,bad,x:y,2:deviceuuid:set",
+		"key_oid":  "pcibusid"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typed.Int("key_oid") != 4 {
+		t.Fatal("expected an invalid key_oid to fall back to 4, got ", typed.Int("key_oid"))
+	}
+	mappings := typed.Mappings("mapping")
+	if len(mappings) != 2 || mappings[0][1] != "devicename" || mappings[1][1] != "deviceuuid" {
+		t.Fatal("expected the malformed mapping entries to be skipped, got ", mappings)
+	}
+	if _, err = rules.ParseParams(gpuTable, paramsOf(map[string]string{
+		"oid_base": ".1.3.6.1.4.1.53246.1.1.1.1", "mapping": "bad,x:y"})); err == nil {
+		t.Fatal("expected an error for a mapping list without a valid entry")
+	}
+
+	for _, spec := range gpuTable {
+		var value string
+		switch spec.Name {
+		case "mapping":
+			value = "1:devicename:set,bad"
+		case "key_oid":
+			value = "pcibusid"
+		default:
+			continue
+		}
+		if _, err = rules.ParseParamValue(spec, value); err == nil {
+			t.Fatal("expected the validator to report ", spec.Name, " value ", value)
+		}
+	}
+
+	toTable := (&rules.StringToCTable{}).ParamSchema()
+	typed, err = rules.ParseParams(toTable, paramsOf(map[string]string{rules.Columns: "4"}))
+	if err != nil {
+		t.Fatal("expected key_column to be optional: ", err)
+	}
+	if typed.Has(rules.KeyColumn) || len((&rules.StringToCTable{}).ParamNames()) != 1 {
+		t.Fatal("expected only columns to be required")
+	}
+}
@@ -0,0 +1,117 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// failingRule always fails.
+type failingRule struct{}
+
+func (this *failingRule) Name() string         { return "TestFailingRule" }
+func (this *failingRule) ParamNames() []string { return []string{} }
+func (this *failingRule) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	return errors.New("failing on purpose")
+}
+
+// TestErrorPolicies verifies that the parser fails fast by default, that ContinueOnError
+// runs the remaining attributes and reports a partial result, and that a failing required
+// attribute aborts the job under either policy.
+func TestErrorPolicies(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+	res.Introspector().Inspect(&types2.NetworkDevice{})
+	parsing.RegisterRule(&failingRule{})
+
+	var unset parsing.ErrorPolicy
+	if unset != parsing.FailFast || parsing.NewConfig().ErrorPolicy != parsing.FailFast {
+		res.Logger().Fail(t, "expected FailFast to be the default policy, got ", unset)
+		return
+	}
+
+	input := &l8tpollaris.CMap{Data: map[string][]byte{".1.3.6.1.2.1.1.5.0": encode("r1")}}
+	job := &l8tpollaris.CJob{PollarisName: "test", JobName: "policies", HostId: "10.20.30.1", Result: encode(input)}
+	poll := func(required bool) *l8tpollaris.L8Poll {
+		failing := &l8tpollaris.L8PRule{Name: "TestFailingRule", Params: map[string]*l8tpollaris.L8PParameter{}}
+		if required {
+			failing.Params[rules.Required] = &l8tpollaris.L8PParameter{Name: rules.Required, Value: "true"}
+		}
+		return &l8tpollaris.L8Poll{Name: "policies", Attributes: []*l8tpollaris.L8PAttribute{
+			{PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.location"},
+				Rules: []*l8tpollaris.L8PRule{failing}},
+			{PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.sysname"},
+				Rules: []*l8tpollaris.L8PRule{{Name: "Set", Params: map[string]*l8tpollaris.L8PParameter{
+					"from": {Name: "from", Value: ".1.3.6.1.2.1.1.5.0"}}}}},
+		}}
+	}
+
+	cases := []struct {
+		name     string
+		policy   parsing.ErrorPolicy
+		required bool
+		partial  bool
+	}{
+		{"FailFast", parsing.FailFast, false, false},
+		{"ContinueOnError", parsing.ContinueOnError, false, true},
+		{"RequiredFailFast", parsing.FailFast, true, false},
+		{"RequiredContinueOnError", parsing.ContinueOnError, true, false},
+	}
+	for _, c := range cases {
+		device := &types2.NetworkDevice{Id: job.HostId}
+		result, err := parsing.Parser.ParsePollWithPolicy(job, poll(c.required), "networkdevice", device, res, c.policy)
+		var parseErrors *parsing.ParseErrors
+		if result == nil || !errors.As(err, &parseErrors) {
+			res.Logger().Fail(t, c.name, ": expected a result and ParseErrors, got ", err)
+			return
+		}
+		if len(parseErrors.Errors) != 1 || parseErrors.Errors[0].Rule != "TestFailingRule" ||
+			parseErrors.Errors[0].PropertyId != "networkdevice.equipmentinfo.location" ||
+			parseErrors.Errors[0].Required != c.required {
+			res.Logger().Fail(t, c.name, ": unexpected attribute errors ", err)
+			return
+		}
+		if parsing.IsPartial(err) != c.partial || parseErrors.Fatal() == c.partial || parseErrors.Aborted == c.partial {
+			res.Logger().Fail(t, c.name, ": expected partial=", c.partial, " for ", err)
+			return
+		}
+		// the second attribute only runs when the job was not aborted
+		sysName := ""
+		if device.Equipmentinfo != nil {
+			sysName = device.Equipmentinfo.SysName
+		}
+		if c.partial != (sysName == "r1") || c.partial != (len(result.Attributes) == 2) {
+			res.Logger().Fail(t, c.name, ": unexpected attributes executed, sysName=", sysName)
+			return
+		}
+		if result.Attributes[0].FailedRule != "TestFailingRule" || result.Attributes[0].Error == nil {
+			res.Logger().Fail(t, c.name, ": expected the failure on the first attribute result")
+			return
+		}
+	}
+
+	if parsing.IsPartial(errors.New("not a parse error")) || parsing.IsPartial(nil) {
+		res.Logger().Fail(t, "expected only ParseErrors to be partial")
+	}
+}
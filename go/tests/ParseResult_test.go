@@ -0,0 +1,165 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/pollaris"
+	"github.com/saichler/l8pollaris/go/pollaris/targets"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+	common2 "github.com/saichler/probler/go/prob/common"
+	types2 "github.com/saichler/probler/go/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestParseResultPipeline verifies that Parse, ParseMulti, ParseJob and ParsePoll run
+// the same pipeline, producing the same element for a map poll and a table poll, and
+// that ParseJob fills in the AttributeResult of every poll attribute.
+func TestParseResultPipeline(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := activateBootPollaris(vnic)
+
+	systemMib := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.2.1.1.1.0": encode("Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11"),
+		".1.3.6.1.2.1.1.2.0": encode(".1.3.6.1.4.1.9.1.1208"),
+		".1.3.6.1.2.1.1.3.0": encode(int64(123456)),
+		".1.3.6.1.2.1.1.5.0": encode("access-sw1"),
+		".1.3.6.1.2.1.1.6.0": encode("lab"),
+	}}
+	job := &l8tpollaris.CJob{PollarisName: "boot01", JobName: "systemMib", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(systemMib)}
+	device := parseAllWays(t, res, job).(*types2.NetworkDevice)
+	if device.Equipmentinfo == nil || device.Equipmentinfo.SysName != "access-sw1" {
+		res.Logger().Fail(t, "expected sysName access-sw1, got ", device.Equipmentinfo)
+		return
+	}
+
+	ifTable := &l8tpollaris.CTable{Rows: map[int32]*l8tpollaris.CRow{
+		1: {Data: map[int32][]byte{1: encode(int64(1)), 2: encode("GigabitEthernet0/1"), 8: encode(int64(1))}},
+		2: {Data: map[int32][]byte{1: encode(int64(2)), 2: encode("GigabitEthernet0/2"), 8: encode(int64(2))}},
+	}}
+	job = &l8tpollaris.CJob{PollarisName: "boot03", JobName: "ifTable", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(ifTable)}
+	device = parseAllWays(t, res, job).(*types2.NetworkDevice)
+	ports := 0
+	for _, physical := range device.Physicals {
+		ports += len(physical.Ports)
+	}
+	if ports != 2 {
+		res.Logger().Fail(t, "expected 2 ports from the ifTable, got ", ports)
+	}
+}
+
+// parseAllWays parses job with each of the parser entry points, failing the test if the
+// parsed elements or the outcomes differ, and returns the element parsed by ParseJob.
+func parseAllWays(t *testing.T, res ifs.IResources, job *l8tpollaris.CJob) proto.Message {
+	poll, err := pollaris.Poll(job.PollarisName, job.JobName, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return nil
+	}
+
+	parsed := &types2.NetworkDevice{Id: job.HostId}
+	result, err := parsing.Parser.ParseJob(job, parsed, res)
+	if result == nil {
+		res.Logger().Fail(t, "ParseJob returned no result: ", err)
+		return nil
+	}
+	if result.Element != parsed || result.Model != "networkdevice" {
+		res.Logger().Fail(t, "unexpected element or model ", result.Model)
+		return nil
+	}
+	checkAttributeResults(t, res, poll, result)
+
+	viaParse := &types2.NetworkDevice{Id: job.HostId}
+	parseErr := parsing.Parser.Parse(job, viaParse, res)
+	viaMulti := &types2.NetworkDevice{Id: job.HostId}
+	_, multiErr := parsing.Parser.ParseMulti(job, viaMulti, res)
+	viaPoll := &types2.NetworkDevice{Id: job.HostId}
+	_, pollErr := parsing.Parser.ParsePoll(job, poll, "networkdevice", viaPoll, res)
+
+	for name, elem := range map[string]*types2.NetworkDevice{"Parse": viaParse, "ParseMulti": viaMulti, "ParsePoll": viaPoll} {
+		if !proto.Equal(parsed, elem) {
+			res.Logger().Fail(t, name, " and ParseJob parsed different elements for ", job.JobName)
+			return nil
+		}
+	}
+	for name, other := range map[string]error{"Parse": parseErr, "ParseMulti": multiErr, "ParsePoll": pollErr} {
+		if (err == nil) != (other == nil) {
+			res.Logger().Fail(t, name, " and ParseJob disagree on the errors of ", job.JobName, ": ", other, " vs ", err)
+			return nil
+		}
+	}
+	return parsed
+}
+
+// checkAttributeResults verifies that the result has an AttributeResult per poll attribute,
+// with the PropertyId, rules and durations of each executed attribute filled in.
+func checkAttributeResults(t *testing.T, res ifs.IResources, poll *l8tpollaris.L8Poll, result *parsing.ParseResult) {
+	if len(result.Attributes) > len(poll.Attributes) || result.Executed() == 0 {
+		res.Logger().Fail(t, "unexpected attribute results for ", poll.Name, ": ", len(result.Attributes))
+		return
+	}
+	for i, attrResult := range result.Attributes {
+		if attrResult.Skipped {
+			continue
+		}
+		attr := poll.Attributes[i]
+		if attrResult.PropertyId != attr.PropertyId["networkdevice"] {
+			res.Logger().Fail(t, "expected PropertyId ", attr.PropertyId["networkdevice"], " got ", attrResult.PropertyId)
+			return
+		}
+		if len(attrResult.Rules) == 0 || len(attrResult.Rules) != len(attrResult.RuleDurations) {
+			res.Logger().Fail(t, "expected executed rules and their durations for ", attrResult.PropertyId)
+			return
+		}
+		if attrResult.Rules[0] != attr.Rules[0].Name {
+			res.Logger().Fail(t, "expected rule ", attr.Rules[0].Name, " first for ", attrResult.PropertyId)
+			return
+		}
+		if (attrResult.Error == nil) != (attrResult.FailedRule == "") {
+			res.Logger().Fail(t, "error and failed rule disagree for ", attrResult.PropertyId)
+			return
+		}
+		if attrResult.Traces != nil {
+			res.Logger().Fail(t, "expected no traces outside of Explain for ", attrResult.PropertyId)
+			return
+		}
+	}
+}
+
+// activateBootPollaris activates the pollaris service with the boot pollaris models and
+// the targets links, so jobs resolve their poll and model as in a running parser.
+func activateBootPollaris(vnic ifs.IVNic) ifs.IResources {
+	initData := []interface{}{}
+	for _, p := range boot.GetAllPolarisModels() {
+		initData = append(initData, p)
+	}
+	sla := ifs.NewServiceLevelAgreement(&pollaris.PollarisService{}, pollaris.ServiceName, pollaris.ServiceArea, true, nil)
+	sla.SetInitItems(initData)
+	vnic.Resources().Services().Activate(sla, vnic)
+	targets.Activate("admin", "admin", vnic)
+
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+	res.Registry().Register(&l8tpollaris.CTable{})
+	res.Introspector().Inspect(&types2.NetworkDevice{})
+	return res
+}
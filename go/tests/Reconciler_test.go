@@ -0,0 +1,134 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// reconcileTypes builds a device message with a list of ports, each with an enum status
+// holding a NOT_PRESENT value and two integer statuses.
+func reconcileTypes(t *testing.T) (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	field := func(name string, number int32, label *descriptorpb.FieldDescriptorProto_Label,
+		typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number),
+			Label: label, Type: typ.Enum(), JsonName: proto.String(name)}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+	file := &descriptorpb.FileDescriptorProto{Name: proto.String("reconcile.proto"),
+		Package: proto.String("reconcile"), Syntax: proto.String("proto3")}
+	file.EnumType = []*descriptorpb.EnumDescriptorProto{{Name: proto.String("Status"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: proto.String("STATUS_UNKNOWN"), Number: proto.Int32(0)},
+			{Name: proto.String("STATUS_UP"), Number: proto.Int32(1)},
+			{Name: proto.String("STATUS_NOT_PRESENT"), Number: proto.Int32(2)},
+		}}}
+	file.MessageType = []*descriptorpb.DescriptorProto{
+		{Name: proto.String("Port"), Field: []*descriptorpb.FieldDescriptorProto{
+			field("id", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+			field("oper_status", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".reconcile.Status"),
+			field("if_status", 3, optional, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+			field("admin_status", 4, optional, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+		}},
+		{Name: proto.String("Device"), Field: []*descriptorpb.FieldDescriptorProto{
+			field("ports", 1, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".reconcile.Port"),
+		}},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Device"), fd.Messages().ByName("Port")
+}
+
+// TestReconciler verifies that a port missing from the current poll is re-added with
+// its status set to NOT_PRESENT, that only the configured integer status is set to
+// notPresent(6), that ports present in both polls are left as is and that a re-added
+// port is dropped once carried for the ghost max age.
+func TestReconciler(t *testing.T) {
+	deviceType, portType := reconcileTypes(t)
+	ports := deviceType.Fields().ByName("ports")
+	newDevice := func(ids ...string) *dynamicpb.Message {
+		device := dynamicpb.NewMessage(deviceType)
+		list := device.Mutable(ports).List()
+		for _, id := range ids {
+			port := dynamicpb.NewMessage(portType)
+			port.Set(portType.Fields().ByName("id"), protoreflect.ValueOfString(id))
+			port.Set(portType.Fields().ByName("oper_status"), protoreflect.ValueOfEnum(1))
+			port.Set(portType.Fields().ByName("if_status"), protoreflect.ValueOfInt32(1))
+			port.Set(portType.Fields().ByName("admin_status"), protoreflect.ValueOfInt32(1))
+			list.Append(protoreflect.ValueOfMessage(port))
+		}
+		return device
+	}
+
+	reconciler := parsing.NewReconciler(0, "reconcile.Port.if_status")
+	if reconciler.Reconcile("mib2.ifTable/10.20.30.1", newDevice("1", "2", "3")) != 0 {
+		t.Fatal("expected nothing to reconcile on the first poll")
+	}
+	device := newDevice("1", "3")
+	if reconciler.Reconcile("mib2.ifTable/10.20.30.1", device) != 1 {
+		t.Fatal("expected one removed port")
+	}
+
+	list := device.Get(ports).List()
+	if list.Len() != 3 {
+		t.Fatal("expected the removed port to be re-added, got ", list.Len(), " ports")
+	}
+	for i := 0; i < list.Len(); i++ {
+		port := list.Get(i).Message()
+		id := port.Get(portType.Fields().ByName("id")).String()
+		status := port.Get(portType.Fields().ByName("oper_status")).Enum()
+		if (id == "2") != (status == 2) {
+			t.Fatal("unexpected status ", status, " for port ", id)
+		}
+		ifStatus := port.Get(portType.Fields().ByName("if_status")).Int()
+		if (id == "2") != (ifStatus == 6) {
+			t.Fatal("unexpected if_status ", ifStatus, " for port ", id)
+		}
+		if port.Get(portType.Fields().ByName("admin_status")).Int() != 1 {
+			t.Fatal("expected the unconfigured integer status to be left as is for port ", id)
+		}
+	}
+
+	reconciler = parsing.NewReconciler(50 * time.Millisecond)
+	reconciler.Reconcile("mib2.ifTable/10.20.30.1", newDevice("1", "2"))
+	if reconciler.Reconcile("mib2.ifTable/10.20.30.1", newDevice("1")) != 1 ||
+		reconciler.Reconcile("mib2.ifTable/10.20.30.1", newDevice("1")) != 1 {
+		t.Fatal("expected the removed port to be carried before the ghost max age")
+	}
+	time.Sleep(100 * time.Millisecond)
+	device = newDevice("1")
+	if reconciler.Reconcile("mib2.ifTable/10.20.30.1", device) != 0 || device.Get(ports).List().Len() != 1 {
+		t.Fatal("expected the removed port to be dropped after the ghost max age")
+	}
+	if reconciler.Reconcile("mib2.ifTable/10.20.30.1", newDevice("1")) != 0 {
+		t.Fatal("expected a dropped port not to come back")
+	}
+}
@@ -0,0 +1,174 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/boot"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+)
+
+// TestRedactJob verifies that key, pattern and JSON path rules mask the values of a
+// CMap job, that the redacted job still decodes, and that the original job is untouched.
+func TestRedactJob(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+
+	cmap := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.4.1.9.9.1": encode("public"),
+		"config":             encode("hostname r1\nsnmp community: private\n"),
+		"secret":             encode(`{"metadata":{"name":"db"},"data":{"password":"c2VjcmV0"}}`),
+		"uptime":             encode(int64(1234)),
+	}}
+	job := &l8tpollaris.CJob{PollarisName: "test", JobName: "test", Result: encode(cmap)}
+
+	redactor, err := parsing.NewRedactor(
+		&parsing.RedactionRule{PollarisName: "test", Keys: []string{".1.3.6.1.4.1.9.9.1"}},
+		&parsing.RedactionRule{Pattern: `(community:\s*)\S+`},
+		&parsing.RedactionRule{JSONPaths: []string{"data"}},
+		&parsing.RedactionRule{PollarisName: "other", All: true})
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+
+	redacted, err := redactor.Redact(job, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	if redacted == job {
+		res.Logger().Fail(t, "expected a redacted copy of the job")
+		return
+	}
+
+	values := decodeMap(t, res, redacted.Result)
+	expected := map[string]interface{}{
+		".1.3.6.1.4.1.9.9.1": parsing.RedactedValue,
+		"config":             "hostname r1\nsnmp community: " + parsing.RedactedValue + "\n",
+		"secret":             `{"data":"` + parsing.RedactedValue + `","metadata":{"name":"db"}}`,
+		"uptime":             int64(1234),
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			res.Logger().Fail(t, "expected ", key, " to be ", value, " but got ", values[key])
+			return
+		}
+	}
+
+	original := decodeMap(t, res, job.Result)
+	if original["config"] != "hostname r1\nsnmp community: private\n" {
+		res.Logger().Fail(t, "original job was modified")
+		return
+	}
+}
+
+// TestDefaultRedactionRules verifies the default rules against jobs of the boot pollaris
+// models: the kubectl pod details lose their env values and last applied configuration
+// but keep the rest of the document, a credential named CMap key is masked and the values
+// of a systemMib job are persisted as collected.
+func TestDefaultRedactionRules(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+	redactor, err := parsing.NewRedactor(parsing.DefaultRedactionRules()...)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+
+	details := false
+	for _, p := range boot.GetAllPolarisModels() {
+		if p.Name == "kubernetes" && p.Polling["poddetails"] != nil {
+			details = true
+		}
+	}
+	if !details {
+		res.Logger().Fail(t, "expected the boot kubernetes pollaris to collect the pod details")
+		return
+	}
+
+	pod := `{"kind":"Pod","metadata":{"name":"db-0","namespace":"prod","annotations":{` +
+		`"kubectl.kubernetes.io/last-applied-configuration":"{\"env\":[{\"name\":\"DB_PASSWORD\",\"value\":\"hunter2\"}]}",` +
+		`"prometheus.io/scrape":"true"}},"spec":{"containers":[{"name":"db","image":"postgres:16",` +
+		`"env":[{"name":"DB_PASSWORD","value":"hunter2"},{"name":"DB_HOST","value":"db.prod"}]}]}}`
+	job := &l8tpollaris.CJob{PollarisName: "kubernetes", JobName: "poddetails", HostId: "lab", Result: encode(pod)}
+	redacted, err := redactor.Redact(job, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	value, err := object.NewDecode(redacted.Result, 0, res.Registry()).Get()
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	doc := value.(string)
+	if strings.Contains(doc, "hunter2") || strings.Contains(doc, "db.prod") {
+		res.Logger().Fail(t, "expected the env values and the last applied configuration to be masked: ", doc)
+		return
+	}
+	for _, kept := range []string{`"name":"DB_PASSWORD"`, `"image":"postgres:16"`, `"prometheus.io/scrape":"true"`, `"name":"db-0"`} {
+		if !strings.Contains(doc, kept) {
+			res.Logger().Fail(t, "expected ", kept, " to be kept: ", doc)
+			return
+		}
+	}
+
+	cmap := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.2.1.1.1.0": encode("Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11"),
+		".1.3.6.1.2.1.1.5.0": encode("access-sw1"),
+		"api_token":          encode("abc123"),
+	}}
+	job = &l8tpollaris.CJob{PollarisName: "boot01", JobName: "systemMib", HostId: "10.20.30.1", Result: encode(cmap)}
+	redacted, err = redactor.Redact(job, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	values := decodeMap(t, res, redacted.Result)
+	if values["api_token"] != parsing.RedactedValue || values[".1.3.6.1.2.1.1.5.0"] != "access-sw1" ||
+		values[".1.3.6.1.2.1.1.1.0"] != "Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11" {
+		res.Logger().Fail(t, "unexpected redacted systemMib values ", values)
+	}
+}
+
+func encode(value interface{}) []byte {
+	enc := object.NewEncode()
+	enc.Add(value)
+	return enc.Data()
+}
+
+func decodeMap(t *testing.T, res ifs.IResources, data []byte) map[string]interface{} {
+	dec := object.NewDecode(data, 0, res.Registry())
+	value, err := dec.Get()
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return nil
+	}
+	result := make(map[string]interface{})
+	for key, raw := range value.(*l8tpollaris.CMap).Data {
+		dec = object.NewDecode(raw, 0, res.Registry())
+		result[key], _ = dec.Get()
+	}
+	return result
+}
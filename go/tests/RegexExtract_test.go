@@ -0,0 +1,52 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestRegexExtract verifies that the named groups of the first matching pattern are
+// extracted, that fallbacks are tried in order and that an invalid fallback is rejected.
+func TestRegexExtract(t *testing.T) {
+	sysDescr := "Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 12.2(55)SE7, RELEASE SOFTWARE (fc1)"
+	groups, first, err := rules.ExtractGroups(sysDescr,
+		`JUNOS (?P<version>\S+)`,
+		`Version (?P<version>[^,\s]+).*?(?P<release>RELEASE|EARLY)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "version" || groups["version"] != "12.2(55)SE7" || groups["release"] != "RELEASE" {
+		t.Fatal("unexpected groups ", groups, " first ", first)
+	}
+
+	groups, _, err = rules.ExtractGroups("Palo Alto Networks PA-3220 series firewall", `Version (?P<version>\S+)`)
+	if err != nil || groups != nil {
+		t.Fatal("expected no match, got ", groups, err)
+	}
+
+	rule := &rules.RegexExtract{}
+	params := map[string]*l8tpollaris.L8PParameter{
+		"pattern":  {Name: "pattern", Value: `Version (?P<version>\S+)`},
+		"fallback": {Name: "fallback", Value: "v(?P<version>\\d\\S*)\n(?P<version>[0-9"},
+	}
+	if _, err := rules.ParseParams(rule.ParamSchema(), params); err == nil {
+		t.Fatal("expected the invalid fallback pattern to be rejected")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/replay"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	common2 "github.com/saichler/probler/go/prob/common"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestReplay records a systemMib job with the job store, replays the recorded file
+// offline and verifies the model is inferred, the key is set from the HostId and the
+// attributes are parsed. It also verifies a job of an unregistered model is reported.
+func TestReplay(t *testing.T) {
+	replay.RegisterBootModels()
+	models := strings.Join(replay.Models(), ",")
+	if !strings.Contains(models, "networkdevice") || !strings.Contains(models, "gpudevice") {
+		t.Fatal("expected the boot models to be registered, got ", models)
+	}
+
+	dir := t.TempDir()
+	store := parsing.NewFileJobStore(&parsing.JobStoreConfig{Dir: dir, Compress: true})
+	systemMib := &l8tpollaris.CMap{Data: map[string][]byte{
+		".1.3.6.1.2.1.1.1.0": encode("Cisco IOS Software, C2960 Software (C2960-LANBASEK9-M), Version 15.0(2)SE11"),
+		".1.3.6.1.2.1.1.2.0": encode(".1.3.6.1.4.1.9.1.1208"),
+		".1.3.6.1.2.1.1.5.0": encode("access-sw1"),
+	}}
+	job := &l8tpollaris.CJob{PollarisName: "boot01", JobName: "systemMib", HostId: "10.20.30.1",
+		TargetId: "10.20.30.1", LinksId: common2.NetworkDevice_Links_ID, Result: encode(systemMib)}
+	err := store.Save(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := store.History(job.PollarisName, job.JobName, job.TargetId, job.HostId)
+	if err != nil || len(records) != 1 {
+		t.Fatal("expected one recorded job, got ", len(records), " ", err)
+	}
+
+	replayer := replay.NewReplayer(replay.NewResources(), false)
+	file := filepath.Join(dir, job.TargetId, records[0].File)
+	result := replayer.RunFile(file, "")
+	if result.Parse == nil || result.Model != "networkdevice" {
+		t.Fatal("failed to replay ", file, ": ", result.Model, " ", result.Error)
+	}
+	device, ok := result.Parse.Element.(*types2.NetworkDevice)
+	if !ok || device.Id != job.HostId || device.Equipmentinfo == nil || device.Equipmentinfo.SysName != "access-sw1" {
+		t.Fatal("unexpected replayed element ", result.Parse.Element)
+	}
+
+	result = replayer.Run(&l8tpollaris.CJob{PollarisName: "kubernetes", JobName: "pods", HostId: "lab"}, "k8spod")
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "not registered") {
+		t.Fatal("expected an unregistered model error, got ", result.Error)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8srlz/go/serialize/object"
+	"github.com/saichler/l8types/go/ifs"
+	"google.golang.org/protobuf/proto"
+)
+
+// sentBatch is a batch of elements sent by an Outbox.
+type sentBatch struct {
+	serviceName string
+	action      ifs.Action
+	elements    []interface{}
+}
+
+// recordingSender records the batches it is asked to send.
+type recordingSender struct {
+	mtx     sync.Mutex
+	batches []*sentBatch
+}
+
+func (this *recordingSender) send(serviceName string, serviceArea byte, action ifs.Action, elements []interface{}) error {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.batches = append(this.batches, &sentBatch{serviceName: serviceName, action: action, elements: elements})
+	return nil
+}
+
+func (this *recordingSender) sent() []*sentBatch {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	return append([]*sentBatch{}, this.batches...)
+}
+
+// TestOutbox verifies that the outbox keeps the elements until its batch is full or it is
+// flushed, and sends them in order, batching consecutive elements of the same action.
+func TestOutbox(t *testing.T) {
+	sender := &recordingSender{}
+	outbox := parsing.NewOutbox(sender.send, 10, time.Hour)
+	defer outbox.Close()
+	outbox.Add("a", "Cache", 1, ifs.PATCH)
+	outbox.Add("b", "Cache", 1, ifs.PATCH)
+	outbox.Add("c", "Cache", 1, ifs.DELETE)
+	outbox.Add("d", "Cache", 1, ifs.PATCH)
+	if outbox.Pending() != 4 || len(sender.sent()) != 0 {
+		t.Fatal("expected 4 pending elements and nothing sent")
+	}
+	if err := outbox.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	batches := sender.sent()
+	if len(batches) != 3 || outbox.Pending() != 0 ||
+		batches[0].action != ifs.PATCH || len(batches[0].elements) != 2 ||
+		batches[1].action != ifs.DELETE || batches[1].elements[0] != "c" ||
+		batches[2].action != ifs.PATCH || batches[2].elements[0] != "d" {
+		t.Fatal("expected the elements in order in 3 batches, got ", len(batches))
+	}
+
+	sender = &recordingSender{}
+	full := parsing.NewOutbox(sender.send, 2, time.Hour)
+	defer full.Close()
+	full.Add("a", "Cache", 1, ifs.PATCH)
+	full.Add("b", "Cache", 1, ifs.PATCH)
+	if batches = sender.sent(); len(batches) != 1 || len(batches[0].elements) != 2 {
+		t.Fatal("expected a full batch to be sent")
+	}
+}
+
+// TestOutboxDropped verifies that the elements of failed batches, of a discard and added
+// after Close are counted per service and action, and make the shutdown report unclean.
+func TestOutboxDropped(t *testing.T) {
+	failing := func(serviceName string, serviceArea byte, action ifs.Action, elements []interface{}) error {
+		return errors.New("no leader")
+	}
+	outbox := parsing.NewOutbox(failing, 10, time.Hour)
+	outbox.Add("a", "Cache", 1, ifs.PATCH)
+	outbox.Add("b", "Cache", 1, ifs.PATCH)
+	outbox.Add("c", "Cache", 1, ifs.DELETE)
+	if err := outbox.Flush(); err == nil {
+		t.Fatal("expected the failed batches to be reported")
+	}
+	outbox.Add("d", "Cache", 1, ifs.PATCH)
+	outbox.Close()
+	if err := outbox.Add("e", "Cache", 1, ifs.PATCH); err != parsing.ErrOutboxClosed {
+		t.Fatal("expected an element added after Close to be rejected, got ", err)
+	}
+	if outbox.Discard() != 1 || outbox.Pending() != 0 {
+		t.Fatal("expected the pending element to be discarded")
+	}
+	expected := map[string]int{"Cache/1 PATCH": 4, "Cache/1 DELETE": 1}
+	if dropped := outbox.Dropped(); !reflect.DeepEqual(dropped, expected) {
+		t.Fatal("expected ", expected, " dropped, got ", dropped)
+	}
+
+	report := &parsing.ShutdownReport{Flushed: true, Dropped: outbox.Dropped()}
+	if report.Clean() || !strings.Contains(report.String(), "Cache/1 DELETE=1, Cache/1 PATCH=4") {
+		t.Fatal("expected the dropped elements in the report, got ", report.String())
+	}
+}
+
+// TestShutdownFlushesPending verifies that DeActivate sends the elements still pending in
+// the outbox and reports a clean flush.
+func TestShutdownFlushesPending(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	sender := &recordingSender{}
+	config := parsing.NewConfig()
+	config.Sender = sender.send
+	config.ShutdownTimeout = 5 * time.Second
+	svc := &parsing.ParsingService{}
+	sla := ifs.NewServiceLevelAgreement(svc, "ShutdownTest", 0, true, nil)
+	sla.SetServiceItem(&deleteResource{})
+	sla.SetPrimaryKeys("ClusterName", "Key")
+	sla.SetArgs(false, config)
+	if err := svc.Activate(sla, vnic); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proto.Marshal(deleteCMap(map[string]string{"namespace": "prod", "name": "db-0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.HandleDelete(&l8tpollaris.CJob{PollarisName: "kubernetes", JobName: "pods", HostId: "lab",
+		TargetId: "lab", Result: data})
+	if len(sender.sent()) != 0 {
+		t.Fatal("expected the delete to be pending before the shutdown")
+	}
+
+	if err = svc.DeActivate(); err != nil {
+		t.Fatal(err)
+	}
+	batches := sender.sent()
+	if len(batches) != 1 || batches[0].action != ifs.DELETE || len(batches[0].elements) != 1 {
+		t.Fatal("expected the pending delete to be sent at shutdown, got ", len(batches), " batches")
+	}
+	stub := batches[0].elements[0].(*deleteResource)
+	if stub.ClusterName != "lab" || stub.Key != "prod/db-0" {
+		t.Fatal("unexpected delete stub ", stub)
+	}
+
+	// a job posted after the shutdown is dropped, not persisted or parsed
+	svc.Post(object.New(nil, &l8tpollaris.CJob{PollarisName: "kubernetes", JobName: "pods", HostId: "lab",
+		TargetId: "lab", Result: data}), vnic)
+	if len(sender.sent()) != 1 {
+		t.Fatal("expected nothing sent for a job posted after the shutdown")
+	}
+}
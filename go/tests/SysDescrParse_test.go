@@ -0,0 +1,67 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+)
+
+// sysDescrFixture is a recorded sysDescr with its expected parse. An empty Platform
+// means the sysDescr must not be recognized.
+type sysDescrFixture struct {
+	SysDescr string `json:"sysDescr"`
+	Platform string `json:"platform"`
+	Software string `json:"software"`
+	Version  string `json:"version"`
+	Model    string `json:"model"`
+	Series   string `json:"series"`
+	Family   string `json:"family"`
+}
+
+// TestParseSysDescr verifies the vendor sysDescr parsers against the recorded fixtures.
+func TestParseSysDescr(t *testing.T) {
+	data, err := os.ReadFile("./testdata/sysdescr/fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures := make([]*sysDescrFixture, 0)
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		parsed := rules.ParseSysDescr(fixture.SysDescr)
+		if fixture.Platform == "" {
+			if parsed != nil {
+				t.Error("expected '", fixture.SysDescr, "' not to be recognized, got ", parsed.Platform)
+			}
+			continue
+		}
+		if parsed == nil {
+			t.Error("expected '", fixture.SysDescr, "' to be recognized as ", fixture.Platform)
+			continue
+		}
+		actual := sysDescrFixture{SysDescr: fixture.SysDescr, Platform: parsed.Platform, Software: parsed.Software,
+			Version: parsed.Version, Model: parsed.Model, Series: parsed.Series, Family: parsed.Family}
+		if actual != *fixture {
+			t.Errorf("sysDescr '%s'\nexpected %+v\nactual   %+v", fixture.SysDescr, *fixture, actual)
+		}
+	}
+}
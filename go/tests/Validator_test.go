@@ -0,0 +1,78 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// TestValidateAllPollaris validates every boot pollaris against the Parser rule
+// registry and the NetworkDevice model.
+func TestValidateAllPollaris(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	vnic.Resources().Introspector().Inspect(&types2.NetworkDevice{})
+
+	report := parsing.ValidateAllPollaris(vnic.Resources(), "networkdevice")
+	if !report.Valid() {
+		vnic.Resources().Logger().Fail(t, report.String())
+		return
+	}
+}
+
+// TestValidatePollarisReportsIssues verifies that an unknown rule, missing required
+// parameters, a non numeric parameter and an unresolvable PropertyId are all reported.
+func TestValidatePollarisReportsIssues(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	vnic.Resources().Introspector().Inspect(&types2.NetworkDevice{})
+
+	p := &l8tpollaris.L8Pollaris{Name: "invalid"}
+	p.Polling = make(map[string]*l8tpollaris.L8Poll)
+	poll := &l8tpollaris.L8Poll{Name: "invalidPoll"}
+	poll.Attributes = []*l8tpollaris.L8PAttribute{
+		{
+			PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.vendor"},
+			Rules: []*l8tpollaris.L8PRule{
+				{Name: "NoSuchRule"},
+				{Name: "Contains", Params: map[string]*l8tpollaris.L8PParameter{
+					"what": {Name: "what", Value: "cisco"},
+				}},
+			},
+		},
+		{
+			PropertyId: map[string]string{"networkdevice": "networkdevice.nosuchfield"},
+			Rules: []*l8tpollaris.L8PRule{
+				{Name: "StringToCTable", Params: map[string]*l8tpollaris.L8PParameter{
+					"columns": {Name: "columns", Value: "abc"},
+				}},
+			},
+		},
+	}
+	p.Polling[poll.Name] = poll
+
+	report := parsing.ValidatePollaris(p, vnic.Resources(), "networkdevice")
+	if report.Valid() {
+		vnic.Resources().Logger().Fail(t, "Expected the pollaris to be invalid")
+		return
+	}
+	if len(report.Errors()) != 5 {
+		vnic.Resources().Logger().Fail(t, "Expected 5 errors, got: ", report.String())
+		return
+	}
+}
@@ -0,0 +1,155 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+)
+
+// TestWorkerPoolOrdering verifies that the jobs of each host are handled in the order
+// they were submitted while hosts are handled concurrently.
+func TestWorkerPoolOrdering(t *testing.T) {
+	mtx := &sync.Mutex{}
+	handled := make(map[string][]int)
+	pool := parsing.NewWorkerPool(4, 10, 0, func(job *l8tpollaris.CJob) {
+		seq, _ := strconv.Atoi(job.JobName)
+		mtx.Lock()
+		handled[job.HostId] = append(handled[job.HostId], seq)
+		mtx.Unlock()
+	})
+
+	hosts := []string{"10.20.30.1", "10.20.30.2", "10.20.30.3", "10.20.30.4", "10.20.30.5"}
+	for seq := 0; seq < 50; seq++ {
+		for _, host := range hosts {
+			err := pool.Submit(&l8tpollaris.CJob{LinksId: "NetDev", HostId: host, JobName: strconv.Itoa(seq)})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	pool.Close()
+
+	for _, host := range hosts {
+		if len(handled[host]) != 50 {
+			t.Fatal("expected 50 jobs for ", host, ", got ", len(handled[host]))
+		}
+		for i, seq := range handled[host] {
+			if seq != i {
+				t.Fatal("jobs of ", host, " handled out of order: ", handled[host])
+			}
+		}
+	}
+
+	if pool.Submit(&l8tpollaris.CJob{HostId: hosts[0]}) != parsing.ErrPoolClosed {
+		t.Fatal("expected submit on a closed pool to fail")
+	}
+}
+
+// TestWorkerPoolBackpressure verifies that a full queue drops jobs after the submit timeout.
+func TestWorkerPoolBackpressure(t *testing.T) {
+	release := make(chan bool)
+	pool := parsing.NewWorkerPool(1, 1, 10*time.Millisecond, func(job *l8tpollaris.CJob) {
+		<-release
+	})
+
+	job := &l8tpollaris.CJob{HostId: "10.20.30.1"}
+	// The first job occupies the worker, the second fills the queue
+	for i := 0; i < 2; i++ {
+		err := pool.Submit(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pool.Submit(job) != parsing.ErrQueueFull {
+		t.Fatal("expected the third job to be dropped")
+	}
+	if pool.Dropped() != 1 {
+		t.Fatal("expected 1 dropped job, got ", pool.Dropped())
+	}
+
+	close(release)
+	pool.Close()
+}
+
+// TestWorkerPoolShutdownTimeout verifies that a shutdown timing out abandons the queued
+// jobs and reports the job still being handled.
+func TestWorkerPoolShutdownTimeout(t *testing.T) {
+	release := make(chan bool)
+	pool := parsing.NewWorkerPool(1, 5, 0, func(job *l8tpollaris.CJob) {
+		<-release
+	})
+	for i := 0; i < 4; i++ {
+		err := pool.Submit(&l8tpollaris.CJob{HostId: "10.20.30.1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	abandoned, inProgress := pool.Shutdown(20 * time.Millisecond)
+	if abandoned != 3 || inProgress != 1 {
+		t.Fatal("expected 3 abandoned and 1 in progress job, got ", abandoned, " and ", inProgress)
+	}
+	close(release)
+}
+
+// TestWorkerPoolShutdownReleasesSenders verifies that a Submit blocked on a full queue
+// without a submit timeout does not hold up the shutdown, and fails with ErrPoolClosed.
+func TestWorkerPoolShutdownReleasesSenders(t *testing.T) {
+	release := make(chan bool)
+	pool := parsing.NewWorkerPool(1, 1, 0, func(job *l8tpollaris.CJob) {
+		<-release
+	})
+	job := &l8tpollaris.CJob{HostId: "10.20.30.1"}
+	// The first job occupies the worker, the second fills the queue
+	for i := 0; i < 2; i++ {
+		err := pool.Submit(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- pool.Submit(job)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	start := time.Now()
+	abandoned, inProgress := pool.Shutdown(20 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatal("expected the shutdown to honor its timeout, took ", elapsed)
+	}
+	if abandoned != 1 || inProgress != 1 {
+		t.Fatal("expected 1 abandoned and 1 in progress job, got ", abandoned, " and ", inProgress)
+	}
+	select {
+	case err := <-blocked:
+		if err != parsing.ErrPoolClosed {
+			t.Fatal("expected the blocked submit to fail with ErrPoolClosed, got ", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the blocked submit to be released")
+	}
+	close(release)
+}
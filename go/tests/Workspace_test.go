@@ -0,0 +1,115 @@
+/*
+© 2025 Sharon Aicler (saichler@gmail.com)
+
+Layer 8 Ecosystem is licensed under the Apache License, Version 2.0.
+You may obtain a copy of the License at:
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/saichler/l8parser/go/parser/rules"
+	parsing "github.com/saichler/l8parser/go/parser/service"
+	"github.com/saichler/l8pollaris/go/types/l8tpollaris"
+	"github.com/saichler/l8types/go/ifs"
+	types2 "github.com/saichler/probler/go/types"
+)
+
+// workspaceWriter writes an output and a private key to its attribute workspace.
+type workspaceWriter struct{}
+
+func (this *workspaceWriter) Name() string         { return "TestWorkspaceWriter" }
+func (this *workspaceWriter) ParamNames() []string { return []string{} }
+func (this *workspaceWriter) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	workSpace[rules.Output] = "written"
+	workSpace["private"] = "written"
+	return nil
+}
+
+// workspaceReader records a copy of the attribute workspace it runs in.
+type workspaceReader struct {
+	seen []map[string]interface{}
+}
+
+func (this *workspaceReader) Name() string         { return "TestWorkspaceReader" }
+func (this *workspaceReader) ParamNames() []string { return []string{} }
+func (this *workspaceReader) Parse(resources ifs.IResources, workSpace map[string]interface{}, params map[string]*l8tpollaris.L8PParameter, any interface{}, pollWhat string) error {
+	seen := make(map[string]interface{}, len(workSpace))
+	for key, value := range workSpace {
+		seen[key] = value
+	}
+	this.seen = append(this.seen, seen)
+	return nil
+}
+
+// TestWorkspaceScopes verifies that what a rule writes to its attribute workspace,
+// including its params, is seen by the next rule of the same attribute but not by
+// the rules of the next attribute, and that a param cannot shadow the job input.
+func TestWorkspaceScopes(t *testing.T) {
+	vnic := topo.VnicByVnetNum(2, 2)
+	res := vnic.Resources()
+	res.Registry().Register(&l8tpollaris.CMap{})
+	reader := &workspaceReader{}
+	parsing.RegisterRule(&workspaceWriter{})
+	parsing.RegisterRule(reader)
+
+	marker := map[string]*l8tpollaris.L8PParameter{
+		"marker":    {Name: "marker", Value: "first"},
+		rules.Input: {Name: rules.Input, Value: "shadowed"},
+	}
+	poll := &l8tpollaris.L8Poll{Name: "workspace", Attributes: []*l8tpollaris.L8PAttribute{
+		{PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.sysname"},
+			Rules: []*l8tpollaris.L8PRule{{Name: "TestWorkspaceWriter", Params: marker}, {Name: "TestWorkspaceReader"}}},
+		{PropertyId: map[string]string{"networkdevice": "networkdevice.equipmentinfo.location"},
+			Rules: []*l8tpollaris.L8PRule{{Name: "TestWorkspaceReader"}}},
+	}}
+	input := &l8tpollaris.CMap{Data: map[string][]byte{".1.3.6.1.2.1.1.5.0": encode("r1")}}
+	job := &l8tpollaris.CJob{PollarisName: "test", JobName: "workspace", HostId: "10.20.30.1", Ended: 1700000000,
+		Result: encode(input)}
+
+	_, err := parsing.Parser.ParsePoll(job, poll, "networkdevice", &types2.NetworkDevice{}, res)
+	if err != nil {
+		res.Logger().Fail(t, err.Error())
+		return
+	}
+	if len(reader.seen) != 2 {
+		res.Logger().Fail(t, "expected the reader to run twice, ran ", len(reader.seen))
+		return
+	}
+
+	same, next := reader.seen[0], reader.seen[1]
+	if same[rules.Output] != "written" || same["private"] != "written" || same["marker"] != "first" {
+		res.Logger().Fail(t, "expected the writer's values in its own attribute workspace: ", same)
+		return
+	}
+	for _, key := range []string{rules.Output, "private", "marker"} {
+		if _, ok := next[key]; ok {
+			res.Logger().Fail(t, "key ", key, " leaked to the next attribute's workspace")
+			return
+		}
+	}
+	if next[rules.PropertyId] != "networkdevice.equipmentinfo.location" {
+		res.Logger().Fail(t, "expected the next attribute's PropertyId, got ", next[rules.PropertyId])
+		return
+	}
+	if next[rules.TargetId] != "10.20.30.1" || next[rules.JobEnded] != int64(1700000000) {
+		res.Logger().Fail(t, "expected the job scope in the next attribute's workspace: ", next)
+		return
+	}
+	for _, seen := range reader.seen {
+		if _, ok := seen[rules.Input].(*l8tpollaris.CMap); !ok {
+			res.Logger().Fail(t, "expected the job input, got ", seen[rules.Input])
+			return
+		}
+	}
+}